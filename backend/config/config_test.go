@@ -0,0 +1,84 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadFileUnknownKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    []string
+	}{
+		{
+			name:    "exemplo sem chaves desconhecidas",
+			file:    "cinedrome.yaml",
+			content: readExample(t),
+		},
+		{
+			name:    "yaml com erros de digitação",
+			file:    "cinedrome.yaml",
+			content: "cors:\n  allowed_origin: [https://a.example]\nauth:\n  sesion_ttl: 1h\n",
+			want:    []string{"allowed_origin", "sesion_ttl"},
+		},
+		{
+			name:    "toml com erros de digitação",
+			file:    "cinedrome.toml",
+			content: "[cors]\nallowed_origin = [\"https://a.example\"]\n\n[auth]\nsesion_ttl = \"1h\"\n",
+			want:    []string{"cors.allowed_origin", "auth.sesion_ttl"},
+		},
+		{
+			name: "yaml vazio",
+			file: "cinedrome.yaml",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			problems, err := loadFile(path, Default())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(problems) != len(tt.want) {
+				t.Fatalf("problemas = %q, esperado %d", problems, len(tt.want))
+			}
+			for i, key := range tt.want {
+				if !strings.Contains(problems[i], key) {
+					t.Errorf("problema %q não menciona %s", problems[i], key)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadRequirements(t *testing.T) {
+	t.Setenv("DB_CONN", "postgres://localhost/cinedrome")
+	t.Setenv("TMDB_ACCESS_TOKEN", "")
+	t.Setenv("CINEDROME_CONFIG", "")
+
+	if _, err := Load(nil); err != nil {
+		t.Errorf("sem requisitos, Load = %v", err)
+	}
+
+	_, err := Load(nil, RequireTMDB)
+	if err == nil || !strings.Contains(err.Error(), "tmdb.access_token") {
+		t.Errorf("com RequireTMDB, Load = %v", err)
+	}
+}
+
+func readExample(t *testing.T) string {
+	data, err := os.ReadFile("cinedrome.example.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var hostLabel = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
+
+// OriginMatcher decide se uma origem está liberada para CORS. Aceita origens
+// exatas ("https://app.exemplo.com") e curingas de subdomínio
+// ("https://*.exemplo.com"), que casam com um ou mais níveis de subdomínio
+// mas nunca com o próprio domínio nem com domínios que apenas terminem
+// com o mesmo texto (como "https://malexemplo.com").
+type OriginMatcher struct {
+	any      bool
+	exact    map[string]bool
+	patterns []originPattern
+}
+
+type originPattern struct {
+	scheme string
+	suffix string
+	port   string
+}
+
+func (c CORSConfig) Matcher() (*OriginMatcher, error) {
+	m := &OriginMatcher{exact: map[string]bool{}}
+	for _, origin := range c.AllowedOrigins {
+		if origin == "*" {
+			if c.AllowCredentials {
+				return nil, fmt.Errorf("cors: origem \"*\" não pode ser usada com allow_credentials")
+			}
+			m.any = true
+			continue
+		}
+
+		pattern, exact, err := parseOrigin(origin)
+		if err != nil {
+			return nil, err
+		}
+		if pattern != nil {
+			m.patterns = append(m.patterns, *pattern)
+		} else {
+			m.exact[exact] = true
+		}
+	}
+	return m, nil
+}
+
+func (m *OriginMatcher) Allowed(origin string) bool {
+	if m.any {
+		return true
+	}
+
+	u, err := url.Parse(strings.ToLower(origin))
+	if err != nil || u.Host == "" || u.User != nil || (u.Path != "" && u.Path != "/") {
+		return false
+	}
+	normalized := u.Scheme + "://" + u.Host
+	if m.exact[normalized] {
+		return true
+	}
+
+	host := u.Hostname()
+	for _, p := range m.patterns {
+		if u.Scheme != p.scheme || u.Port() != p.port || !strings.HasSuffix(host, "."+p.suffix) {
+			continue
+		}
+		if validLabels(strings.TrimSuffix(host, "."+p.suffix)) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseOrigin devolve o padrão de curinga ou a origem exata normalizada.
+func parseOrigin(origin string) (*originPattern, string, error) {
+	invalid := fmt.Errorf("cors: origem inválida %q", origin)
+
+	lower := strings.ToLower(strings.TrimSpace(origin))
+	scheme, rest, ok := strings.Cut(lower, "://")
+	if !ok || (scheme != "http" && scheme != "https") || rest == "" || strings.ContainsAny(rest, "/?#@") {
+		return nil, "", invalid
+	}
+
+	wildcard := strings.HasPrefix(rest, "*.")
+	hostPort := strings.TrimPrefix(rest, "*.")
+	if strings.Contains(hostPort, "*") {
+		// Curingas só são aceitos como o primeiro rótulo inteiro.
+		return nil, "", invalid
+	}
+
+	u, err := url.Parse(scheme + "://" + hostPort)
+	if err != nil || !validLabels(u.Hostname()) {
+		return nil, "", invalid
+	}
+
+	if !wildcard {
+		return nil, scheme + "://" + u.Host, nil
+	}
+
+	// "*.com" liberaria qualquer site: o domínio base precisa de pelo menos dois rótulos.
+	if !strings.Contains(u.Hostname(), ".") {
+		return nil, "", fmt.Errorf("cors: curinga amplo demais em %q", origin)
+	}
+	return &originPattern{scheme: scheme, suffix: u.Hostname(), port: u.Port()}, "", nil
+}
+
+func validLabels(host string) bool {
+	if host == "" {
+		return false
+	}
+	for _, label := range strings.Split(host, ".") {
+		if !hostLabel.MatchString(label) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,91 @@
+package config
+
+import "testing"
+
+func TestOriginMatcherWildcard(t *testing.T) {
+	m, err := CORSConfig{AllowedOrigins: []string{"https://*.example.com"}}.Matcher()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		origin string
+		want   bool
+	}{
+		{"https://app.example.com", true},
+		{"https://a.b.example.com", true},
+		{"https://APP.Example.com", true},
+		{"https://evilexample.com", false},
+		{"https://app.evilexample.com", false},
+		{"https://example.com", false},
+		{"https://app.example.com:8443", false},
+		{"http://app.example.com", false},
+		{"https://app.example.com.evil.com", false},
+		{"https://user@app.example.com", false},
+		{"https://app.example.com/path", false},
+		{"null", false},
+	}
+	for _, tt := range tests {
+		if got := m.Allowed(tt.origin); got != tt.want {
+			t.Errorf("Allowed(%q) = %v, esperado %v", tt.origin, got, tt.want)
+		}
+	}
+}
+
+func TestOriginMatcherWildcardPort(t *testing.T) {
+	m, err := CORSConfig{AllowedOrigins: []string{"http://*.example.com:3000"}}.Matcher()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !m.Allowed("http://app.example.com:3000") {
+		t.Error("porta igual deveria ser aceita")
+	}
+	if m.Allowed("http://app.example.com") || m.Allowed("http://app.example.com:3001") {
+		t.Error("porta diferente não deveria ser aceita")
+	}
+}
+
+func TestOriginMatcherExact(t *testing.T) {
+	m, err := CORSConfig{AllowedOrigins: []string{"https://app.example.com"}}.Matcher()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !m.Allowed("https://app.example.com") {
+		t.Error("origem exata deveria ser aceita")
+	}
+	for _, origin := range []string{"http://app.example.com", "https://app.example.com:444", "https://other.example.com"} {
+		if m.Allowed(origin) {
+			t.Errorf("Allowed(%q) deveria ser false", origin)
+		}
+	}
+}
+
+func TestMatcherRejectsInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  CORSConfig
+	}{
+		{"curinga de TLD", CORSConfig{AllowedOrigins: []string{"https://*.com"}}},
+		{"curinga no meio do host", CORSConfig{AllowedOrigins: []string{"https://app.*.example.com"}}},
+		{"origem com caminho", CORSConfig{AllowedOrigins: []string{"https://example.com/app"}}},
+		{"esquema desconhecido", CORSConfig{AllowedOrigins: []string{"ftp://example.com"}}},
+		{"* com credenciais", CORSConfig{AllowedOrigins: []string{"*"}, AllowCredentials: true}},
+	}
+	for _, tt := range tests {
+		if _, err := tt.cfg.Matcher(); err == nil {
+			t.Errorf("%s: configuração deveria ser rejeitada", tt.name)
+		}
+	}
+}
+
+func TestMatcherAnyOrigin(t *testing.T) {
+	m, err := CORSConfig{AllowedOrigins: []string{"*"}}.Matcher()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.Allowed("https://qualquer.site") {
+		t.Error("* sem credenciais deveria aceitar qualquer origem")
+	}
+}
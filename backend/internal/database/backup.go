@@ -0,0 +1,406 @@
+package database
+
+import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	BackupFormat        = "cinedrome-backup"
+	BackupFormatVersion = 1
+
+	backupManifestFile = "manifest.json"
+)
+
+// Tabelas incluídas no backup, na ordem em que precisam ser restauradas
+// para respeitar as chaves estrangeiras.
+var backupTables = []backupTable{
+	// As sessões ficam de fora: um backup restaurado não deve reabrir logins antigos.
+	{Name: "users", OrderBy: "id", Serial: true},
+	{Name: "api_keys", OrderBy: "id", Serial: true},
+	{Name: "filmes", OrderBy: "id", Serial: true},
+	{Name: "people", OrderBy: "id"},
+	{Name: "movie_cast", OrderBy: "tmdb_id, credit_id"},
+	{Name: "movie_crew", OrderBy: "tmdb_id, credit_id"},
+	{Name: "movie_credits_index", OrderBy: "tmdb_id"},
+	{Name: "movie_keywords", OrderBy: "tmdb_id, keyword_id"},
+	{Name: "movie_keywords_fetched", OrderBy: "tmdb_id"},
+	{Name: "tmdb_cache", OrderBy: "key"},
+	{Name: "watchlist", OrderBy: "id", Serial: true},
+	{Name: "lists", OrderBy: "id", Serial: true},
+	{Name: "list_items", OrderBy: "list_id, position"},
+	{Name: "tags", OrderBy: "id", Serial: true},
+	{Name: "movie_tags", OrderBy: "movie_id, tag_id"},
+	{Name: "critics", OrderBy: "username"},
+	{Name: "critic_follows", OrderBy: "user_id, critic"},
+	{Name: "critic_entries", OrderBy: "id", Serial: true},
+	{Name: "critic_similarity", OrderBy: "user_id, critic"},
+}
+
+type backupTable struct {
+	Name    string
+	OrderBy string
+	Serial  bool
+}
+
+// O arquivo é um zip com um manifest.json e um arquivo JSON lines por tabela.
+// As linhas são independentes do banco, então o mesmo arquivo serve para
+// mover dados entre instâncias.
+type BackupManifest struct {
+	Format        string              `json:"format"`
+	FormatVersion int                 `json:"formatVersion"`
+	SchemaVersion int                 `json:"schemaVersion"`
+	CreatedAt     time.Time           `json:"createdAt"`
+	Tables        []BackupTableResult `json:"tables"`
+}
+
+type BackupTableResult struct {
+	Name    string   `json:"name"`
+	File    string   `json:"file"`
+	Rows    int      `json:"rows"`
+	SHA256  string   `json:"sha256"`
+	Columns []string `json:"columns"`
+}
+
+// SchemaVersion retorna a versão mais recente conhecida pelas migrações.
+func SchemaVersion() int {
+	return migrations[len(migrations)-1].Version
+}
+
+func Backup(ctx context.Context, db *sql.DB, w io.Writer) (*BackupManifest, error) {
+	manifest := &BackupManifest{
+		Format:        BackupFormat,
+		FormatVersion: BackupFormatVersion,
+		CreatedAt:     time.Now().UTC(),
+	}
+
+	// Uma transação somente leitura garante um retrato consistente de todas as tabelas.
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao iniciar transação de backup: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, `SELECT coalesce(max(version), 0) FROM public.schema_migrations`).Scan(&manifest.SchemaVersion)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler versão do schema: %w", err)
+	}
+
+	archive := zip.NewWriter(w)
+	for _, table := range backupTables {
+		result, err := backupTableRows(ctx, tx, archive, table)
+		if err != nil {
+			return nil, err
+		}
+		manifest.Tables = append(manifest.Tables, *result)
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao gerar manifest: %w", err)
+	}
+	file, err := archive.Create(backupManifestFile)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao gravar manifest: %w", err)
+	}
+	if _, err := file.Write(data); err != nil {
+		return nil, fmt.Errorf("erro ao gravar manifest: %w", err)
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, fmt.Errorf("erro ao finalizar arquivo de backup: %w", err)
+	}
+
+	return manifest, nil
+}
+
+func backupTableRows(ctx context.Context, tx *sql.Tx, archive *zip.Writer, table backupTable) (*BackupTableResult, error) {
+	columns, err := tableColumns(ctx, tx, table.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &BackupTableResult{
+		Name:    table.Name,
+		File:    "tables/" + table.Name + ".jsonl",
+		Columns: columns,
+	}
+
+	file, err := archive.Create(result.File)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar %s no backup: %w", result.File, err)
+	}
+
+	hash := sha256.New()
+	out := bufio.NewWriter(io.MultiWriter(file, hash))
+
+	query := fmt.Sprintf(`SELECT row_to_json(t) FROM (SELECT %s FROM public.%s ORDER BY %s) t`,
+		quoteIdentifiers(columns), quoteIdentifier(table.Name), table.OrderBy)
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler tabela %s: %w", table.Name, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var line []byte
+		if err := rows.Scan(&line); err != nil {
+			return nil, fmt.Errorf("erro ao ler linha de %s: %w", table.Name, err)
+		}
+		if _, err := out.Write(line); err != nil {
+			return nil, err
+		}
+		if err := out.WriteByte('\n'); err != nil {
+			return nil, err
+		}
+		result.Rows++
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre %s: %w", table.Name, err)
+	}
+
+	if err := out.Flush(); err != nil {
+		return nil, fmt.Errorf("erro ao gravar %s no backup: %w", result.File, err)
+	}
+
+	result.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	return result, nil
+}
+
+// Restore substitui todo o conteúdo das tabelas pelo do arquivo. O banco de
+// destino precisa estar migrado para uma versão igual ou mais nova que a do
+// backup; colunas que não existem mais no destino são ignoradas.
+func Restore(ctx context.Context, db *sql.DB, r io.ReaderAt, size int64) (*BackupManifest, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("arquivo de backup inválido: %w", err)
+	}
+
+	manifest, err := readManifest(archive)
+	if err != nil {
+		return nil, err
+	}
+	if manifest.SchemaVersion > SchemaVersion() {
+		return nil, fmt.Errorf("backup usa schema %d, mais novo que o suportado (%d)", manifest.SchemaVersion, SchemaVersion())
+	}
+
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, file := range archive.File {
+		files[file.Name] = file
+	}
+
+	entries := make(map[string]BackupTableResult, len(manifest.Tables))
+	for _, entry := range manifest.Tables {
+		file, ok := files[entry.File]
+		if !ok {
+			return nil, fmt.Errorf("arquivo %s ausente no backup", entry.File)
+		}
+		if err := verifyChecksum(file, entry.SHA256); err != nil {
+			return nil, err
+		}
+		entries[entry.Name] = entry
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao iniciar transação de restauração: %w", err)
+	}
+	defer tx.Rollback()
+
+	names := make([]string, 0, len(backupTables))
+	for _, table := range backupTables {
+		names = append(names, "public."+quoteIdentifier(table.Name))
+	}
+	if _, err := tx.ExecContext(ctx, `TRUNCATE `+strings.Join(names, ", ")+` RESTART IDENTITY CASCADE`); err != nil {
+		return nil, fmt.Errorf("erro ao limpar tabelas: %w", err)
+	}
+
+	for _, table := range backupTables {
+		entry, ok := entries[table.Name]
+		if !ok {
+			continue
+		}
+		if err := restoreTableRows(ctx, tx, files[entry.File], table, entry); err != nil {
+			return nil, err
+		}
+		if table.Serial {
+			_, err := tx.ExecContext(ctx, fmt.Sprintf(
+				`SELECT setval(pg_get_serial_sequence('public.%[1]s', 'id'), coalesce(max(id), 0) + 1, false) FROM public.%[1]s`,
+				table.Name))
+			if err != nil {
+				return nil, fmt.Errorf("erro ao ajustar sequência de %s: %w", table.Name, err)
+			}
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, backfillCreditsIndexSQL); err != nil {
+		return nil, fmt.Errorf("erro ao reconstruir índice de créditos: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, backfillKeywordsFetchedSQL); err != nil {
+		return nil, fmt.Errorf("erro ao marcar palavras-chave buscadas: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, claimOrphanRowsSQL); err != nil {
+		return nil, fmt.Errorf("erro ao atribuir dados sem dono: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("erro ao confirmar restauração: %w", err)
+	}
+
+	return manifest, nil
+}
+
+func restoreTableRows(ctx context.Context, tx *sql.Tx, file *zip.File, table backupTable, entry BackupTableResult) error {
+	current, err := tableColumns(ctx, tx, table.Name)
+	if err != nil {
+		return err
+	}
+
+	// Só as colunas presentes dos dois lados; as demais ficam com o default do destino.
+	available := make(map[string]bool, len(current))
+	for _, column := range current {
+		available[column] = true
+	}
+	var columns []string
+	for _, column := range entry.Columns {
+		if available[column] {
+			columns = append(columns, column)
+		}
+	}
+	if len(columns) == 0 {
+		return nil
+	}
+
+	list := quoteIdentifiers(columns)
+	stmt, err := tx.PrepareContext(ctx, fmt.Sprintf(
+		`INSERT INTO public.%[1]s (%[2]s) SELECT %[2]s FROM json_populate_record(NULL::public.%[1]s, $1::json)`,
+		quoteIdentifier(table.Name), list))
+	if err != nil {
+		return fmt.Errorf("erro ao preparar restauração de %s: %w", table.Name, err)
+	}
+	defer stmt.Close()
+
+	rc, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("erro ao abrir %s: %w", entry.File, err)
+	}
+	defer rc.Close()
+
+	scanner := bufio.NewScanner(rc)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	count := 0
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		if _, err := stmt.ExecContext(ctx, string(line)); err != nil {
+			return fmt.Errorf("erro ao restaurar linha %d de %s: %w", count+1, table.Name, err)
+		}
+		count++
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("erro ao ler %s: %w", entry.File, err)
+	}
+
+	if count != entry.Rows {
+		return fmt.Errorf("tabela %s: esperadas %d linhas, restauradas %d", table.Name, entry.Rows, count)
+	}
+
+	return nil
+}
+
+func readManifest(archive *zip.Reader) (*BackupManifest, error) {
+	file, err := archive.Open(backupManifestFile)
+	if err != nil {
+		return nil, fmt.Errorf("manifest ausente no backup: %w", err)
+	}
+	defer file.Close()
+
+	var manifest BackupManifest
+	if err := json.NewDecoder(file).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("manifest inválido: %w", err)
+	}
+
+	if manifest.Format != BackupFormat {
+		return nil, fmt.Errorf("formato de backup desconhecido: %q", manifest.Format)
+	}
+	if manifest.FormatVersion > BackupFormatVersion {
+		return nil, fmt.Errorf("versão de backup %d não suportada", manifest.FormatVersion)
+	}
+
+	return &manifest, nil
+}
+
+func verifyChecksum(file *zip.File, expected string) error {
+	rc, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("erro ao abrir %s: %w", file.Name, err)
+	}
+	defer rc.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, rc); err != nil {
+		return fmt.Errorf("erro ao ler %s: %w", file.Name, err)
+	}
+
+	if hex.EncodeToString(hash.Sum(nil)) != expected {
+		return errors.New("checksum inválido para " + file.Name)
+	}
+	return nil
+}
+
+// Colunas graváveis da tabela; colunas geradas (como search_vector) ficam de fora.
+func tableColumns(ctx context.Context, tx *sql.Tx, table string) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT column_name
+		FROM information_schema.columns
+		WHERE table_schema = 'public' AND table_name = $1 AND is_generated = 'NEVER'
+		ORDER BY ordinal_position`, table)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler colunas de %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return nil, fmt.Errorf("erro ao ler colunas de %s: %w", table, err)
+		}
+		columns = append(columns, column)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao ler colunas de %s: %w", table, err)
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("tabela %s não encontrada", table)
+	}
+
+	return columns, nil
+}
+
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func quoteIdentifiers(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = quoteIdentifier(name)
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,492 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+type migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+var migrations = []migration{
+	{
+		Version: 1,
+		Name:    "create_filmes",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS public.filmes (
+				id                   SERIAL PRIMARY KEY,
+				title                TEXT NOT NULL DEFAULT '',
+				year                 VARCHAR(4) NOT NULL DEFAULT '',
+				watched_date         DATE,
+				member_rating        VARCHAR(8) NOT NULL DEFAULT '',
+				description          TEXT NOT NULL DEFAULT '',
+				imdb_rating          VARCHAR(8) NOT NULL DEFAULT '',
+				genre                TEXT NOT NULL DEFAULT '',
+				plot                 TEXT NOT NULL DEFAULT '',
+				director             TEXT NOT NULL DEFAULT '',
+				tmdb_id              VARCHAR(32) NOT NULL DEFAULT '',
+				runtime              INTEGER NOT NULL DEFAULT 0,
+				release_date         DATE,
+				budget               BIGINT NOT NULL DEFAULT 0,
+				revenue              BIGINT NOT NULL DEFAULT 0,
+				tagline              TEXT NOT NULL DEFAULT '',
+				status               TEXT NOT NULL DEFAULT '',
+				original_language    TEXT NOT NULL DEFAULT '',
+				production_companies TEXT NOT NULL DEFAULT '',
+				spoken_languages     TEXT NOT NULL DEFAULT '',
+				poster_path          TEXT NOT NULL DEFAULT '',
+				backdrop_path        TEXT NOT NULL DEFAULT '',
+				homepage             TEXT NOT NULL DEFAULT '',
+				guid                 TEXT NOT NULL UNIQUE
+			);`,
+	},
+	{
+		Version: 2,
+		Name:    "full_text_search",
+		SQL: `
+			CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+			ALTER TABLE public.filmes ADD COLUMN IF NOT EXISTS original_title TEXT NOT NULL DEFAULT '';
+
+			ALTER TABLE public.filmes ADD COLUMN IF NOT EXISTS search_vector tsvector
+				GENERATED ALWAYS AS (
+					setweight(to_tsvector('portuguese', coalesce(title, '')), 'A') ||
+					setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+					setweight(to_tsvector('simple', coalesce(original_title, '')), 'A') ||
+					setweight(to_tsvector('simple', coalesce(director, '')), 'B') ||
+					setweight(to_tsvector('portuguese', coalesce(tagline, '')), 'C') ||
+					setweight(to_tsvector('portuguese', coalesce(plot, '')), 'C') ||
+					setweight(to_tsvector('english', coalesce(plot, '')), 'C') ||
+					setweight(to_tsvector('portuguese', regexp_replace(coalesce(description, ''), '<[^>]*>', ' ', 'g')), 'D') ||
+					setweight(to_tsvector('english', regexp_replace(coalesce(description, ''), '<[^>]*>', ' ', 'g')), 'D')
+				) STORED;
+
+			CREATE INDEX IF NOT EXISTS filmes_search_vector_idx ON public.filmes USING GIN (search_vector);
+			CREATE INDEX IF NOT EXISTS filmes_title_trgm_idx ON public.filmes USING GIN (title gin_trgm_ops);
+			CREATE INDEX IF NOT EXISTS filmes_original_title_trgm_idx ON public.filmes USING GIN (original_title gin_trgm_ops);`,
+	},
+	{
+		Version: 3,
+		Name:    "production_countries",
+		SQL: `
+			ALTER TABLE public.filmes ADD COLUMN IF NOT EXISTS production_countries TEXT NOT NULL DEFAULT '';`,
+	},
+	{
+		Version: 4,
+		Name:    "rewatch",
+		SQL: `
+			ALTER TABLE public.filmes ADD COLUMN IF NOT EXISTS rewatch BOOLEAN NOT NULL DEFAULT false;`,
+	},
+	{
+		Version: 5,
+		Name:    "credits",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS public.people (
+				id           INTEGER PRIMARY KEY,
+				name         TEXT NOT NULL,
+				gender       INTEGER NOT NULL DEFAULT 0,
+				profile_path TEXT
+			);
+
+			CREATE TABLE IF NOT EXISTS public.movie_cast (
+				tmdb_id   VARCHAR(32) NOT NULL,
+				credit_id TEXT NOT NULL,
+				person_id INTEGER NOT NULL REFERENCES public.people (id),
+				cast_id   INTEGER NOT NULL DEFAULT 0,
+				character TEXT NOT NULL DEFAULT '',
+				ord       INTEGER NOT NULL DEFAULT 0,
+				PRIMARY KEY (tmdb_id, credit_id)
+			);
+
+			CREATE TABLE IF NOT EXISTS public.movie_crew (
+				tmdb_id    VARCHAR(32) NOT NULL,
+				credit_id  TEXT NOT NULL,
+				person_id  INTEGER NOT NULL REFERENCES public.people (id),
+				department TEXT NOT NULL DEFAULT '',
+				job        TEXT NOT NULL DEFAULT '',
+				PRIMARY KEY (tmdb_id, credit_id)
+			);
+
+			CREATE INDEX IF NOT EXISTS movie_cast_person_idx ON public.movie_cast (person_id);
+			CREATE INDEX IF NOT EXISTS movie_crew_person_idx ON public.movie_crew (person_id);
+			CREATE INDEX IF NOT EXISTS filmes_tmdb_id_idx ON public.filmes (tmdb_id);`,
+	},
+	{
+		Version: 6,
+		Name:    "tmdb_cache",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS public.tmdb_cache (
+				key        TEXT PRIMARY KEY,
+				payload    JSONB NOT NULL,
+				fetched_at TIMESTAMPTZ NOT NULL DEFAULT now()
+			);`,
+	},
+	{
+		Version: 7,
+		Name:    "movie_source",
+		SQL: `
+			ALTER TABLE public.filmes ADD COLUMN IF NOT EXISTS source TEXT NOT NULL DEFAULT 'letterboxd';`,
+	},
+	{
+		Version: 8,
+		Name:    "watchlist",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS public.watchlist (
+				id             SERIAL PRIMARY KEY,
+				tmdb_id        VARCHAR(32) NOT NULL UNIQUE,
+				title          TEXT NOT NULL DEFAULT '',
+				original_title TEXT NOT NULL DEFAULT '',
+				year           VARCHAR(4) NOT NULL DEFAULT '',
+				genre          TEXT NOT NULL DEFAULT '',
+				plot           TEXT NOT NULL DEFAULT '',
+				runtime        INTEGER NOT NULL DEFAULT 0,
+				poster_path    TEXT NOT NULL DEFAULT '',
+				letterboxd_uri TEXT NOT NULL DEFAULT '',
+				source         TEXT NOT NULL DEFAULT 'manual',
+				added_date     DATE NOT NULL DEFAULT CURRENT_DATE
+			);`,
+	},
+	{
+		Version: 9,
+		Name:    "lists_and_tags",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS public.lists (
+				id          SERIAL PRIMARY KEY,
+				name        TEXT NOT NULL,
+				description TEXT NOT NULL DEFAULT '',
+				source_url  TEXT NOT NULL DEFAULT '',
+				created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+			);
+
+			CREATE TABLE IF NOT EXISTS public.list_items (
+				list_id     INTEGER NOT NULL REFERENCES public.lists (id) ON DELETE CASCADE,
+				tmdb_id     VARCHAR(32) NOT NULL,
+				title       TEXT NOT NULL DEFAULT '',
+				year        VARCHAR(4) NOT NULL DEFAULT '',
+				poster_path TEXT NOT NULL DEFAULT '',
+				position    INTEGER NOT NULL,
+				notes       TEXT NOT NULL DEFAULT '',
+				PRIMARY KEY (list_id, tmdb_id)
+			);
+
+			CREATE TABLE IF NOT EXISTS public.tags (
+				id   SERIAL PRIMARY KEY,
+				name TEXT NOT NULL
+			);
+			CREATE UNIQUE INDEX IF NOT EXISTS tags_name_idx ON public.tags (lower(name));
+
+			CREATE TABLE IF NOT EXISTS public.movie_tags (
+				movie_id INTEGER NOT NULL REFERENCES public.filmes (id) ON DELETE CASCADE,
+				tag_id   INTEGER NOT NULL REFERENCES public.tags (id) ON DELETE CASCADE,
+				PRIMARY KEY (movie_id, tag_id)
+			);`,
+	},
+	{
+		Version: 10,
+		Name:    "movie_timestamps",
+		SQL: `
+			ALTER TABLE public.filmes ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT now();
+			ALTER TABLE public.filmes ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+			CREATE INDEX IF NOT EXISTS filmes_updated_at_idx ON public.filmes (updated_at);`,
+	},
+	{
+		Version: 11,
+		Name:    "users_and_sessions",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS public.users (
+				id            SERIAL PRIMARY KEY,
+				username      TEXT NOT NULL UNIQUE,
+				password_hash TEXT NOT NULL,
+				created_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+			);
+			CREATE TABLE IF NOT EXISTS public.sessions (
+				token_hash TEXT PRIMARY KEY,
+				user_id    INTEGER NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+				created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+				expires_at TIMESTAMPTZ NOT NULL
+			);
+			CREATE INDEX IF NOT EXISTS sessions_user_id_idx ON public.sessions (user_id);
+			CREATE INDEX IF NOT EXISTS sessions_expires_at_idx ON public.sessions (expires_at);`,
+	},
+	{
+		Version: 12,
+		Name:    "multi_user",
+		SQL: `
+			ALTER TABLE public.users ADD COLUMN IF NOT EXISTS letterboxd_username TEXT NOT NULL DEFAULT '';
+			ALTER TABLE public.users ADD COLUMN IF NOT EXISTS rss_file_path TEXT NOT NULL DEFAULT '';
+
+			ALTER TABLE public.filmes ADD COLUMN IF NOT EXISTS user_id INTEGER REFERENCES public.users (id) ON DELETE CASCADE;
+			CREATE INDEX IF NOT EXISTS filmes_user_id_idx ON public.filmes (user_id, watched_date);
+
+			ALTER TABLE public.watchlist ADD COLUMN IF NOT EXISTS user_id INTEGER REFERENCES public.users (id) ON DELETE CASCADE;
+			ALTER TABLE public.watchlist DROP CONSTRAINT IF EXISTS watchlist_tmdb_id_key;
+			CREATE UNIQUE INDEX IF NOT EXISTS watchlist_user_tmdb_idx ON public.watchlist (user_id, tmdb_id);
+
+			ALTER TABLE public.lists ADD COLUMN IF NOT EXISTS user_id INTEGER REFERENCES public.users (id) ON DELETE CASCADE;
+			CREATE INDEX IF NOT EXISTS lists_user_id_idx ON public.lists (user_id);
+
+			ALTER TABLE public.tags ADD COLUMN IF NOT EXISTS user_id INTEGER REFERENCES public.users (id) ON DELETE CASCADE;
+			DROP INDEX IF EXISTS public.tags_name_idx;
+			CREATE UNIQUE INDEX IF NOT EXISTS tags_user_name_idx ON public.tags (user_id, lower(name));
+` + claimOrphanRowsSQL,
+	},
+	{
+		Version: 13,
+		Name:    "api_keys",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS public.api_keys (
+				id           SERIAL PRIMARY KEY,
+				user_id      INTEGER NOT NULL REFERENCES public.users (id) ON DELETE CASCADE,
+				name         TEXT NOT NULL,
+				prefix       TEXT NOT NULL,
+				key_hash     TEXT NOT NULL UNIQUE,
+				scope        TEXT NOT NULL CHECK (scope IN ('read', 'write')),
+				rate_limit   INTEGER NOT NULL CHECK (rate_limit > 0),
+				created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+				last_used_at TIMESTAMPTZ
+			);
+			CREATE INDEX IF NOT EXISTS api_keys_user_id_idx ON public.api_keys (user_id);`,
+	},
+	{
+		Version: 14,
+		Name:    "critics",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS public.critics (
+				username       TEXT PRIMARY KEY,
+				display_name   TEXT NOT NULL DEFAULT '',
+				last_synced_at TIMESTAMPTZ
+			);
+			CREATE TABLE IF NOT EXISTS public.critic_follows (
+				user_id    INTEGER NOT NULL REFERENCES public.users (id) ON DELETE CASCADE,
+				critic     TEXT NOT NULL REFERENCES public.critics (username) ON DELETE CASCADE,
+				created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+				PRIMARY KEY (user_id, critic)
+			);
+			CREATE TABLE IF NOT EXISTS public.critic_entries (
+				id            SERIAL PRIMARY KEY,
+				critic        TEXT NOT NULL REFERENCES public.critics (username) ON DELETE CASCADE,
+				guid          TEXT NOT NULL UNIQUE,
+				tmdb_id       VARCHAR(32) NOT NULL DEFAULT '',
+				title         TEXT NOT NULL DEFAULT '',
+				year          VARCHAR(4) NOT NULL DEFAULT '',
+				watched_date  DATE,
+				member_rating VARCHAR(8) NOT NULL DEFAULT '',
+				review        TEXT NOT NULL DEFAULT '',
+				rewatch       BOOLEAN NOT NULL DEFAULT false,
+				link          TEXT NOT NULL DEFAULT '',
+				created_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+			);
+			CREATE INDEX IF NOT EXISTS critic_entries_tmdb_id_idx ON public.critic_entries (tmdb_id);
+			CREATE INDEX IF NOT EXISTS critic_entries_critic_idx ON public.critic_entries (critic, watched_date);`,
+	},
+	{
+		Version: 15,
+		Name:    "critic_similarity",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS public.critic_similarity (
+				user_id       INTEGER NOT NULL,
+				critic        TEXT NOT NULL,
+				overlap       INTEGER NOT NULL DEFAULT 0,
+				pearson       DOUBLE PRECISION,
+				spearman      DOUBLE PRECISION,
+				mean_abs_diff DOUBLE PRECISION,
+				computed_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+				PRIMARY KEY (user_id, critic),
+				FOREIGN KEY (user_id, critic) REFERENCES public.critic_follows (user_id, critic) ON DELETE CASCADE
+			);`,
+	},
+	{
+		Version: 16,
+		Name:    "critic_agreement",
+		SQL: `
+			-- Preenchida no próximo recálculo de cada par.
+			ALTER TABLE public.critic_similarity ADD COLUMN IF NOT EXISTS agreement DOUBLE PRECISION;`,
+	},
+	{
+		Version: 17,
+		Name:    "movie_keywords",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS public.movie_keywords (
+				tmdb_id    VARCHAR(32) NOT NULL,
+				keyword_id INTEGER NOT NULL,
+				name       TEXT NOT NULL,
+				PRIMARY KEY (tmdb_id, keyword_id)
+			);
+
+			CREATE INDEX IF NOT EXISTS movie_keywords_keyword_idx ON public.movie_keywords (keyword_id);`,
+	},
+	{
+		Version: 18,
+		Name:    "search_vector_simple_reviews",
+		SQL: `
+			-- As resenhas misturam idiomas; com 'simple' nenhuma delas passa
+			-- pelo stemmer errado. Títulos e sinopses, que vêm do TMDb em
+			-- pt-BR com fallback em inglês, continuam com os dois stemmers.
+			DROP INDEX IF EXISTS public.filmes_search_vector_idx;
+			ALTER TABLE public.filmes DROP COLUMN IF EXISTS search_vector;
+
+			ALTER TABLE public.filmes ADD COLUMN search_vector tsvector
+				GENERATED ALWAYS AS (
+					setweight(to_tsvector('portuguese', coalesce(title, '')), 'A') ||
+					setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+					setweight(to_tsvector('simple', coalesce(original_title, '')), 'A') ||
+					setweight(to_tsvector('simple', coalesce(director, '')), 'B') ||
+					setweight(to_tsvector('portuguese', coalesce(tagline, '')), 'C') ||
+					setweight(to_tsvector('portuguese', coalesce(plot, '')), 'C') ||
+					setweight(to_tsvector('english', coalesce(plot, '')), 'C') ||
+					setweight(to_tsvector('simple', regexp_replace(coalesce(description, ''), '<[^>]*>', ' ', 'g')), 'D')
+				) STORED;
+
+			CREATE INDEX IF NOT EXISTS filmes_search_vector_idx ON public.filmes USING GIN (search_vector);`,
+	},
+	{
+		Version: 19,
+		Name:    "movie_credits_index",
+		SQL: `
+			-- Uma linha por filme cujos créditos já vieram do TMDb, mesmo que
+			-- vazios. cast_vector guarda os nomes do elenco com índice próprio,
+			-- para a busca não agregar os créditos linha a linha.
+			CREATE TABLE IF NOT EXISTS public.movie_credits_index (
+				tmdb_id     VARCHAR(32) PRIMARY KEY,
+				cast_vector tsvector NOT NULL DEFAULT ''::tsvector,
+				fetched_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+			);
+
+			CREATE INDEX IF NOT EXISTS movie_credits_index_cast_idx ON public.movie_credits_index USING GIN (cast_vector);
+` + backfillCreditsIndexSQL,
+	},
+	{
+		Version: 20,
+		Name:    "filmes_guid_per_user",
+		SQL: `
+			-- O GUID vem do RSS do Letterboxd: duas contas ligadas ao mesmo
+			-- perfil recebem os mesmos itens, então a unicidade é por usuário.
+			ALTER TABLE public.filmes DROP CONSTRAINT IF EXISTS filmes_guid_key;
+			CREATE UNIQUE INDEX IF NOT EXISTS filmes_user_guid_idx ON public.filmes (user_id, guid);`,
+	},
+	{
+		Version: 21,
+		Name:    "movie_keywords_fetched",
+		SQL: `
+			-- Uma linha por filme cujas palavras-chave já vieram do TMDb, mesmo
+			-- que nenhuma, para não buscá-las de novo a cada consulta.
+			CREATE TABLE IF NOT EXISTS public.movie_keywords_fetched (
+				tmdb_id    VARCHAR(32) PRIMARY KEY,
+				fetched_at TIMESTAMPTZ NOT NULL DEFAULT now()
+			);
+` + backfillKeywordsFetchedSQL,
+	},
+	{
+		Version: 22,
+		Name:    "critic_entries_metadata",
+		SQL: `
+			-- Dados do TMDb dos filmes que nenhum diário registrou, buscados ao
+			-- sincronizar o feed do crítico para as recomendações não
+			-- dependerem do TMDb na leitura.
+			ALTER TABLE public.critic_entries ADD COLUMN IF NOT EXISTS genre TEXT NOT NULL DEFAULT '';
+			ALTER TABLE public.critic_entries ADD COLUMN IF NOT EXISTS runtime INTEGER NOT NULL DEFAULT 0;
+			ALTER TABLE public.critic_entries ADD COLUMN IF NOT EXISTS poster_path TEXT NOT NULL DEFAULT '';
+			ALTER TABLE public.critic_entries ADD COLUMN IF NOT EXISTS metadata_fetched_at TIMESTAMPTZ;`,
+	},
+}
+
+// Preenche o índice dos filmes que têm créditos guardados mas ainda não
+// têm linha em movie_credits_index; roda na migração e após restaurar
+// backups anteriores à tabela.
+const backfillCreditsIndexSQL = `
+			INSERT INTO public.movie_credits_index (tmdb_id, cast_vector)
+			SELECT tmdb_id, coalesce(setweight(to_tsvector('simple', string_agg(name, ' ')), 'B'), ''::tsvector)
+			FROM (
+				SELECT c.tmdb_id, p.name
+				FROM public.movie_cast c
+				JOIN public.people p ON p.id = c.person_id
+				UNION ALL
+				SELECT tmdb_id, NULL FROM public.movie_crew
+			) credits
+			GROUP BY tmdb_id
+			ON CONFLICT (tmdb_id) DO NOTHING;`
+
+// Marca como buscados os filmes que já têm palavras-chave guardadas; roda na
+// migração e após restaurar backups anteriores à tabela.
+const backfillKeywordsFetchedSQL = `
+			INSERT INTO public.movie_keywords_fetched (tmdb_id)
+			SELECT DISTINCT tmdb_id FROM public.movie_keywords
+			ON CONFLICT (tmdb_id) DO NOTHING;`
+
+// Os dados da instância de um só usuário passam a ser do primeiro
+// cadastrado; sem usuários, ficam sem dono até o primeiro cadastro. Roda na
+// migração e após restaurar backups anteriores às contas.
+const claimOrphanRowsSQL = `
+			UPDATE public.filmes SET user_id = (SELECT min(id) FROM public.users) WHERE user_id IS NULL;
+			UPDATE public.watchlist SET user_id = (SELECT min(id) FROM public.users) WHERE user_id IS NULL;
+			UPDATE public.lists SET user_id = (SELECT min(id) FROM public.users) WHERE user_id IS NULL;
+			UPDATE public.tags SET user_id = (SELECT min(id) FROM public.users) WHERE user_id IS NULL;`
+
+func Migrate(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS public.schema_migrations (
+			version    INTEGER PRIMARY KEY,
+			name       TEXT NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)`)
+	if err != nil {
+		return fmt.Errorf("erro ao criar tabela de migrações: %w", err)
+	}
+
+	for _, m := range migrations {
+		var applied bool
+		err := db.QueryRow(`SELECT EXISTS(SELECT 1 FROM public.schema_migrations WHERE version=$1)`, m.Version).Scan(&applied)
+		if err != nil {
+			return fmt.Errorf("erro ao verificar migração %d: %w", m.Version, err)
+		}
+		if applied {
+			continue
+		}
+
+		if err := applyMigration(db, m); err != nil {
+			return err
+		}
+		log.Printf("Migração %d (%s) aplicada com sucesso", m.Version, m.Name)
+	}
+
+	return nil
+}
+
+// CurrentVersion retorna a última migração aplicada no banco (0 se nenhuma).
+func CurrentVersion(db *sql.DB) (int, error) {
+	var exists bool
+	if err := db.QueryRow(`SELECT to_regclass('public.schema_migrations') IS NOT NULL`).Scan(&exists); err != nil {
+		return 0, fmt.Errorf("erro ao verificar tabela de migrações: %w", err)
+	}
+	if !exists {
+		return 0, nil
+	}
+
+	var version int
+	if err := db.QueryRow(`SELECT coalesce(max(version), 0) FROM public.schema_migrations`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("erro ao ler versão do schema: %w", err)
+	}
+	return version, nil
+}
+
+func applyMigration(db *sql.DB, m migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar migração %d: %w", m.Version, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(m.SQL); err != nil {
+		return fmt.Errorf("erro ao aplicar migração %d (%s): %w", m.Version, m.Name, err)
+	}
+
+	if _, err := tx.Exec(`INSERT INTO public.schema_migrations (version, name) VALUES ($1, $2)`, m.Version, m.Name); err != nil {
+		return fmt.Errorf("erro ao registrar migração %d: %w", m.Version, err)
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"letterboxd-viewer-backend/internal/middleware"
+	"letterboxd-viewer-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type APIKeyHandler struct {
+	APIKeyService *services.APIKeyService
+	Logger        *log.Logger
+}
+
+func NewAPIKeyHandler(apiKeyService *services.APIKeyService, logger *log.Logger) *APIKeyHandler {
+	return &APIKeyHandler{
+		APIKeyService: apiKeyService,
+		Logger:        logger,
+	}
+}
+
+func (h *APIKeyHandler) SetupRoutes(router *gin.Engine) {
+	api := router.Group("/api/auth/keys", middleware.RequireSession)
+	{
+		api.GET("", h.GetKeys)
+		api.POST("", h.CreateKey)
+		api.DELETE("/:id", h.RevokeKey)
+	}
+}
+
+func (h *APIKeyHandler) GetKeys(c *gin.Context) {
+	keys, err := h.APIKeyService.ListKeys(middleware.CurrentUser(c))
+	if err != nil {
+		h.Logger.Printf("Erro ao buscar chaves de API: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar chaves de API no banco de dados"})
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+// CreateKey é a única resposta que traz a chave completa.
+func (h *APIKeyHandler) CreateKey(c *gin.Context) {
+	var req struct {
+		Name      string `json:"name"`
+		Scope     string `json:"scope"`
+		RateLimit int    `json:"rateLimit"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Corpo da requisição inválido"})
+		return
+	}
+
+	key, token, err := h.APIKeyService.CreateKey(middleware.CurrentUser(c), req.Name, req.Scope, req.RateLimit)
+	switch {
+	case errors.Is(err, services.ErrInvalidAPIKeyName),
+		errors.Is(err, services.ErrInvalidAPIKeyScope),
+		errors.Is(err, services.ErrInvalidAPIKeyRateLimit):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		h.Logger.Printf("Erro ao criar chave de API: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao criar chave de API"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"key":    token,
+		"apiKey": key,
+	})
+}
+
+func (h *APIKeyHandler) RevokeKey(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de chave inválido"})
+		return
+	}
+
+	if err := h.APIKeyService.RevokeKey(middleware.CurrentUser(c), id); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Chave de API não encontrada"})
+			return
+		}
+		h.Logger.Printf("Erro ao revogar chave de API %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao revogar chave de API"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"letterboxd-viewer-backend/internal/middleware"
+	"letterboxd-viewer-backend/internal/repositories"
+	"letterboxd-viewer-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuthHandler struct {
+	AuthService *services.AuthService
+	Logger      *log.Logger
+	// Só confie no X-Forwarded-Proto atrás de um proxy que termina o TLS.
+	TrustForwardedProto bool
+}
+
+type credentialsRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+func NewAuthHandler(authService *services.AuthService, trustForwardedProto bool, logger *log.Logger) *AuthHandler {
+	return &AuthHandler{
+		AuthService:         authService,
+		Logger:              logger,
+		TrustForwardedProto: trustForwardedProto,
+	}
+}
+
+func (h *AuthHandler) SetupRoutes(router *gin.Engine) {
+	api := router.Group("/api/auth")
+	{
+		api.POST("/register", h.Register)
+		api.POST("/login", h.Login)
+		api.POST("/logout", h.Logout)
+		api.GET("/me", middleware.RequireAuth, h.Me)
+		api.PATCH("/me", middleware.RequireSession, h.UpdateMe)
+	}
+}
+
+// Register cria a conta e já abre uma sessão para ela.
+func (h *AuthHandler) Register(c *gin.Context) {
+	var req credentialsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Corpo da requisição inválido"})
+		return
+	}
+
+	_, err := h.AuthService.Register(req.Username, req.Password)
+	switch {
+	case errors.Is(err, services.ErrInvalidUsername), errors.Is(err, services.ErrInvalidPassword):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, services.ErrRegistrationClosed):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, repositories.ErrUsernameTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		h.Logger.Printf("Erro ao cadastrar usuário: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao cadastrar usuário"})
+		return
+	}
+
+	session, err := h.AuthService.Login(req.Username, req.Password)
+	if err != nil {
+		h.Logger.Printf("Erro ao abrir sessão após cadastro: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao abrir sessão"})
+		return
+	}
+
+	h.setSessionCookie(c, session.Token, session.ExpiresAt)
+	c.JSON(http.StatusCreated, session)
+}
+
+func (h *AuthHandler) Login(c *gin.Context) {
+	var req credentialsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Corpo da requisição inválido"})
+		return
+	}
+
+	session, err := h.AuthService.Login(req.Username, req.Password)
+	if errors.Is(err, services.ErrInvalidCredentials) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		h.Logger.Printf("Erro ao autenticar usuário: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao autenticar usuário"})
+		return
+	}
+
+	h.setSessionCookie(c, session.Token, session.ExpiresAt)
+	c.JSON(http.StatusOK, session)
+}
+
+// Logout é idempotente: sem sessão válida, apenas limpa o cookie.
+func (h *AuthHandler) Logout(c *gin.Context) {
+	if token, err := c.Cookie(middleware.SessionCookieName); err == nil {
+		if err := h.AuthService.Logout(token); err != nil {
+			h.Logger.Printf("Erro ao encerrar sessão: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao encerrar sessão"})
+			return
+		}
+	}
+
+	h.setSessionCookie(c, "", time.Time{})
+	c.Status(http.StatusNoContent)
+}
+
+func (h *AuthHandler) Me(c *gin.Context) {
+	c.JSON(http.StatusOK, middleware.CurrentUser(c))
+}
+
+// UpdateMe altera o perfil do usuário logado. O arquivo RSS local só pode
+// ser definido pela linha de comando, para a API não expor arquivos do servidor.
+func (h *AuthHandler) UpdateMe(c *gin.Context) {
+	var req struct {
+		LetterboxdUsername *string `json:"letterboxdUsername"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Corpo da requisição inválido"})
+		return
+	}
+
+	user := middleware.CurrentUser(c)
+	if req.LetterboxdUsername != nil {
+		err := h.AuthService.UpdateLetterboxdUsername(user, *req.LetterboxdUsername)
+		if errors.Is(err, services.ErrInvalidLetterboxdUsername) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			h.Logger.Printf("Erro ao atualizar perfil de %s: %v", user.Username, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar perfil"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// O cookie é HttpOnly e SameSite=Lax, o que já impede que outros sites
+// disparem as rotas de escrita com a sessão do usuário. Um token vazio
+// remove o cookie.
+func (h *AuthHandler) setSessionCookie(c *gin.Context, token string, expiresAt time.Time) {
+	cookie := &http.Cookie{
+		Name:     middleware.SessionCookieName,
+		Value:    token,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   middleware.IsTLS(c, h.TrustForwardedProto),
+		SameSite: http.SameSiteLaxMode,
+	}
+	if token == "" {
+		cookie.MaxAge = -1
+	} else {
+		cookie.Expires = expiresAt
+		cookie.MaxAge = int(time.Until(expiresAt).Seconds())
+	}
+	http.SetCookie(c.Writer, cookie)
+}
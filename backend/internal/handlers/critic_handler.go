@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"letterboxd-viewer-backend/internal/middleware"
+	"letterboxd-viewer-backend/internal/repositories"
+	"letterboxd-viewer-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CriticHandler struct {
+	DB            *sql.DB
+	CriticService *services.CriticService
+	Logger        *log.Logger
+}
+
+func NewCriticHandler(db *sql.DB, criticService *services.CriticService, logger *log.Logger) *CriticHandler {
+	return &CriticHandler{
+		DB:            db,
+		CriticService: criticService,
+		Logger:        logger,
+	}
+}
+
+func (h *CriticHandler) SetupRoutes(router *gin.Engine) {
+	api := router.Group("/api")
+	{
+		api.GET("/critics", h.GetCritics)
+		api.POST("/critics", middleware.RequireAuth, h.FollowCritic)
+		api.POST("/critics/sync", middleware.RequireAuth, h.SyncCritics)
+		api.GET("/critics/similarity", h.GetSimilarity)
+		api.DELETE("/critics/:username", middleware.RequireAuth, h.UnfollowCritic)
+		api.GET("/movie/:guid/critics", h.GetMovieOpinions)
+	}
+}
+
+func (h *CriticHandler) GetCritics(c *gin.Context) {
+	owner, ok := diaryOwner(c, h.DB, h.Logger)
+	if !ok {
+		return
+	}
+
+	critics, err := h.CriticService.Critics.GetFollowedCritics(owner.ID)
+	if err != nil {
+		h.Logger.Printf("Erro ao buscar críticos: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar críticos no banco de dados"})
+		return
+	}
+
+	c.JSON(http.StatusOK, critics)
+}
+
+// FollowCritic já importa o feed do crítico. Se o Letterboxd não responder,
+// o crítico continua acompanhado e entra na próxima sincronização.
+func (h *CriticHandler) FollowCritic(c *gin.Context) {
+	var req struct {
+		Username string `json:"username"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Corpo da requisição inválido"})
+		return
+	}
+
+	username, err := h.CriticService.Follow(middleware.CurrentUser(c), req.Username)
+	switch {
+	case errors.Is(err, services.ErrInvalidCritic):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, repositories.ErrAlreadyFollowing):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		h.Logger.Printf("Erro ao acompanhar crítico %s: %v", req.Username, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao acompanhar crítico"})
+		return
+	}
+
+	result, err := h.CriticService.SyncCritic(c.Request.Context(), username)
+	if err != nil {
+		h.Logger.Printf("Erro ao importar feed de %s: %v", username, err)
+		c.JSON(http.StatusCreated, gin.H{
+			"username":  username,
+			"syncError": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"username": username,
+		"sync":     result,
+	})
+}
+
+func (h *CriticHandler) UnfollowCritic(c *gin.Context) {
+	if err := h.CriticService.Unfollow(middleware.CurrentUser(c), c.Param("username")); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Crítico não acompanhado"})
+			return
+		}
+		h.Logger.Printf("Erro ao deixar de acompanhar %s: %v", c.Param("username"), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao deixar de acompanhar crítico"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *CriticHandler) SyncCritics(c *gin.Context) {
+	results, err := h.CriticService.SyncFollowed(c.Request.Context(), middleware.CurrentUser(c))
+	if err != nil {
+		h.Logger.Printf("Erro ao sincronizar críticos: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao sincronizar críticos"})
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
+
+// GetSimilarity ordena os críticos acompanhados pela concordância com as
+// notas do dono do diário.
+func (h *CriticHandler) GetSimilarity(c *gin.Context) {
+	minOverlap := 0
+	if value := c.Query("minOverlap"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Parâmetro minOverlap inválido"})
+			return
+		}
+		minOverlap = parsed
+	}
+
+	owner, ok := diaryOwner(c, h.DB, h.Logger)
+	if !ok {
+		return
+	}
+
+	similarities, err := h.CriticService.Similarity.GetSimilarities(owner.ID, minOverlap)
+	if err != nil {
+		h.Logger.Printf("Erro ao buscar similaridade com os críticos: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar similaridade com os críticos"})
+		return
+	}
+
+	c.JSON(http.StatusOK, similarities)
+}
+
+// GetMovieOpinions mostra as notas e resenhas dos críticos acompanhados pelo
+// dono do diário ao lado da entrada dele.
+func (h *CriticHandler) GetMovieOpinions(c *gin.Context) {
+	owner, ok := diaryOwner(c, h.DB, h.Logger)
+	if !ok {
+		return
+	}
+
+	movie, err := repositories.GetMovieByGUID(h.DB, owner.ID, c.Param("guid"))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Filme não encontrado"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar filme no banco de dados"})
+		}
+		return
+	}
+
+	opinions, err := h.CriticService.GetOpinions(movie)
+	if err != nil {
+		h.Logger.Printf("Erro ao buscar opiniões sobre %s: %v", movie.GUID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar opiniões dos críticos"})
+		return
+	}
+
+	c.JSON(http.StatusOK, opinions)
+}
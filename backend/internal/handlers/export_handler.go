@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"letterboxd-viewer-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ExportHandler struct {
+	DB     *sql.DB
+	Logger *log.Logger
+}
+
+func NewExportHandler(db *sql.DB, logger *log.Logger) *ExportHandler {
+	return &ExportHandler{
+		DB:     db,
+		Logger: logger,
+	}
+}
+
+func (h *ExportHandler) SetupRoutes(router *gin.Engine) {
+	api := router.Group("/api")
+	{
+		api.GET("/export", h.Export)
+	}
+}
+
+func (h *ExportHandler) Export(c *gin.Context) {
+	format := c.DefaultQuery("format", "csv")
+
+	exportFormat, ok := services.ExportFormats[format]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parâmetro format inválido, use csv, json ou letterboxd"})
+		return
+	}
+
+	owner, ok := diaryOwner(c, h.DB, h.Logger)
+	if !ok {
+		return
+	}
+
+	filename := fmt.Sprintf("cinedrome-%s-%s.%s", format, time.Now().Format("2006-01-02"), exportFormat.Extension)
+	c.Header("Content-Type", exportFormat.ContentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Status(http.StatusOK)
+
+	if err := services.WriteExport(c.Request.Context(), h.DB, owner.ID, c.Writer, format, c.Writer.Flush); err != nil {
+		// O cabeçalho já foi enviado, então só resta interromper a resposta.
+		h.Logger.Printf("Erro ao exportar filmes (%s): %v", format, err)
+		c.Abort()
+	}
+}
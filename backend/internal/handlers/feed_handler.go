@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"letterboxd-viewer-backend/internal/middleware"
+	"letterboxd-viewer-backend/internal/models"
+	"letterboxd-viewer-backend/internal/repositories"
+	"letterboxd-viewer-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultFeedSize = 50
+	maxFeedSize     = 500
+)
+
+type FeedHandler struct {
+	DB          *sql.DB
+	FeedService *services.FeedService
+	Logger      *log.Logger
+}
+
+func NewFeedHandler(db *sql.DB, baseURL string, logger *log.Logger) *FeedHandler {
+	return &FeedHandler{
+		DB:          db,
+		FeedService: services.NewFeedService(baseURL),
+		Logger:      logger,
+	}
+}
+
+func (h *FeedHandler) SetupRoutes(router *gin.Engine) {
+	feeds := router.Group("/feeds")
+	{
+		feeds.GET("/diary.rss", h.serveFeed("application/rss+xml; charset=utf-8", h.FeedService.BuildRSS))
+		feeds.GET("/diary.atom", h.serveFeed("application/atom+xml; charset=utf-8", h.FeedService.BuildAtom))
+		feeds.GET("/diary.json", h.serveFeed("application/feed+json; charset=utf-8",
+			func(movies []models.Movie, _ time.Time) ([]byte, error) {
+				return h.FeedService.BuildJSONFeed(movies)
+			}))
+		feeds.GET("/diary.ics", h.GetCalendar)
+	}
+}
+
+func (h *FeedHandler) serveFeed(contentType string, build func([]models.Movie, time.Time) ([]byte, error)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit := defaultFeedSize
+		if value := c.Query("limit"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed <= 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Parâmetro limit inválido"})
+				return
+			}
+			limit = min(parsed, maxFeedSize)
+		}
+
+		owner, ok := diaryOwner(c, h.DB, h.Logger)
+		if !ok {
+			return
+		}
+
+		count, lastModified, err := repositories.GetDiaryVersion(h.DB, owner.ID)
+		if err != nil {
+			h.Logger.Printf("Erro ao verificar versão do diário: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao gerar feed"})
+			return
+		}
+		if lastModified.IsZero() {
+			lastModified = time.Unix(0, 0)
+		}
+		lastModified = lastModified.UTC().Truncate(time.Second)
+
+		etag := fmt.Sprintf(`W/"%d-%d-%d-%d"`, owner.ID, count, lastModified.Unix(), limit)
+		c.Header("ETag", etag)
+		c.Header("Last-Modified", lastModified.Format(http.TimeFormat))
+		setFeedCacheHeaders(c)
+
+		if notModified(c.Request, etag, lastModified) {
+			c.Status(http.StatusNotModified)
+			return
+		}
+
+		movies, err := repositories.GetRecentMovies(h.DB, owner.ID, limit)
+		if err != nil {
+			h.Logger.Printf("Erro ao buscar filmes para o feed: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao gerar feed"})
+			return
+		}
+
+		body, err := build(movies, lastModified)
+		if err != nil {
+			h.Logger.Printf("Erro ao gerar feed: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao gerar feed"})
+			return
+		}
+
+		c.Data(http.StatusOK, contentType, body)
+	}
+}
+
+func (h *FeedHandler) GetCalendar(c *gin.Context) {
+	year := 0
+	if value := c.Query("year"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1874 || parsed > 9999 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Parâmetro year inválido"})
+			return
+		}
+		year = parsed
+	}
+
+	owner, ok := diaryOwner(c, h.DB, h.Logger)
+	if !ok {
+		return
+	}
+
+	count, lastModified, err := repositories.GetDiaryVersion(h.DB, owner.ID)
+	if err != nil {
+		h.Logger.Printf("Erro ao verificar versão do diário: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao gerar calendário"})
+		return
+	}
+	if lastModified.IsZero() {
+		lastModified = time.Unix(0, 0)
+	}
+	lastModified = lastModified.UTC().Truncate(time.Second)
+
+	etag := fmt.Sprintf(`W/"%d-%d-%d-ics-%d"`, owner.ID, count, lastModified.Unix(), year)
+	c.Header("ETag", etag)
+	c.Header("Last-Modified", lastModified.Format(http.TimeFormat))
+	setFeedCacheHeaders(c)
+
+	if notModified(c.Request, etag, lastModified) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	movies, err := repositories.GetWatchedMovies(h.DB, owner.ID, year)
+	if err != nil {
+		h.Logger.Printf("Erro ao buscar filmes para o calendário: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao gerar calendário"})
+		return
+	}
+
+	filename := "cinedrome-diary.ics"
+	if year != 0 {
+		filename = fmt.Sprintf("cinedrome-diary-%d.ics", year)
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="%s"`, filename))
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", h.FeedService.BuildICS(movies, lastModified))
+}
+
+// setFeedCacheHeaders só libera caches compartilhados quando a resposta não
+// depende de quem pede: sem ?user=, uma sessão ou chave de API troca o diário
+// do dono da instância pelo do usuário autenticado.
+func setFeedCacheHeaders(c *gin.Context) {
+	c.Header("Vary", "Cookie, Authorization")
+	if middleware.CurrentUser(c) != nil && c.Param("username") == "" && c.Query("user") == "" {
+		c.Header("Cache-Control", "private, max-age=300")
+		return
+	}
+	c.Header("Cache-Control", "public, max-age=300")
+}
+
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		for _, candidate := range strings.Split(match, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	if since := r.Header.Get("If-Modified-Since"); since != "" {
+		if t, err := http.ParseTime(since); err == nil && !lastModified.After(t) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"regexp"
+
+	"github.com/mmcdole/gofeed"
+)
+
+var feedTitleYear = regexp.MustCompile(`^(.*), (\d{4})$`)
+
+type filmEntry struct {
+	TMDBId        string
+	Title         string
+	Year          string
+	AddedDate     string
+	LetterboxdURI string
+	Source        string
+	Notes         string
+}
+
+func filmEntryFromFeedItem(item *gofeed.Item, source string) filmEntry {
+	entry := filmEntry{
+		Title:         item.Title,
+		LetterboxdURI: item.Link,
+		Source:        source,
+	}
+
+	if ext, ok := item.Extensions["letterboxd"]; ok {
+		if len(ext["filmTitle"]) > 0 {
+			entry.Title = ext["filmTitle"][0].Value
+		}
+		if len(ext["filmYear"]) > 0 {
+			entry.Year = ext["filmYear"][0].Value
+		}
+	}
+	if entry.Year == "" {
+		if match := feedTitleYear.FindStringSubmatch(item.Title); match != nil {
+			entry.Title, entry.Year = match[1], match[2]
+		}
+	}
+
+	if tmdb, ok := item.Extensions["tmdb"]; ok {
+		if movieId, ok := tmdb["movieId"]; ok && len(movieId) > 0 {
+			entry.TMDBId = movieId[0].Value
+		}
+	}
+
+	if item.PublishedParsed != nil {
+		entry.AddedDate = item.PublishedParsed.Format("2006-01-02")
+	}
+
+	return entry
+}
@@ -0,0 +1,590 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"letterboxd-viewer-backend/internal/middleware"
+	"letterboxd-viewer-backend/internal/models"
+	"letterboxd-viewer-backend/internal/repositories"
+	"letterboxd-viewer-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxTagLength = 64
+
+type ListHandler struct {
+	DB          *sql.DB
+	TMDBService *services.TMDBService
+	Logger      *log.Logger
+}
+
+type listRequest struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+}
+
+type listItemRequest struct {
+	TMDBId   string  `json:"tmdbId"`
+	Notes    *string `json:"notes"`
+	Position *int    `json:"position"`
+}
+
+func NewListHandler(db *sql.DB, tmdbService *services.TMDBService, logger *log.Logger) *ListHandler {
+	return &ListHandler{
+		DB:          db,
+		TMDBService: tmdbService,
+		Logger:      logger,
+	}
+}
+
+func (h *ListHandler) SetupRoutes(router *gin.Engine) {
+	api := router.Group("/api")
+	{
+		api.GET("/movies", h.GetMovies)
+		api.GET("/users/:username/movies", h.GetMovies)
+
+		api.GET("/lists", h.GetLists)
+		api.POST("/lists", middleware.RequireAuth, h.CreateList)
+		api.POST("/lists/import", middleware.RequireAuth, h.ImportList)
+		api.GET("/lists/:id", h.GetList)
+		api.PATCH("/lists/:id", middleware.RequireAuth, h.UpdateList)
+		api.DELETE("/lists/:id", middleware.RequireAuth, h.DeleteList)
+		api.POST("/lists/:id/items", middleware.RequireAuth, h.AddListItem)
+		api.PATCH("/lists/:id/items/:tmdbId", middleware.RequireAuth, h.UpdateListItem)
+		api.DELETE("/lists/:id/items/:tmdbId", middleware.RequireAuth, h.RemoveListItem)
+
+		api.GET("/tags", h.GetTags)
+		api.GET("/movies/:guid/tags", h.GetMovieTags)
+		api.PUT("/movies/:guid/tags", middleware.RequireAuth, h.SetMovieTags)
+		api.POST("/movies/:guid/tags", middleware.RequireAuth, h.AddMovieTag)
+		api.DELETE("/movies/:guid/tags/:tag", middleware.RequireAuth, h.RemoveMovieTag)
+	}
+}
+
+func (h *ListHandler) GetMovies(c *gin.Context) {
+	filter := repositories.MovieFilter{Tag: strings.TrimSpace(c.Query("tag"))}
+	if value := c.Query("list"); value != "" {
+		listId, err := strconv.Atoi(value)
+		if err != nil || listId <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Parâmetro list inválido"})
+			return
+		}
+		filter.ListID = listId
+	}
+
+	owner, ok := diaryOwner(c, h.DB, h.Logger)
+	if !ok {
+		return
+	}
+
+	movies, err := repositories.GetMovies(h.DB, owner.ID, filter)
+	if err != nil {
+		h.Logger.Printf("Erro ao buscar filmes: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar filmes no banco de dados"})
+		return
+	}
+
+	c.JSON(http.StatusOK, movies)
+}
+
+func (h *ListHandler) GetLists(c *gin.Context) {
+	owner, ok := diaryOwner(c, h.DB, h.Logger)
+	if !ok {
+		return
+	}
+
+	lists, err := repositories.NewListRepository(h.DB).GetLists(owner.ID)
+	if err != nil {
+		h.Logger.Printf("Erro ao buscar listas: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar listas no banco de dados"})
+		return
+	}
+
+	c.JSON(http.StatusOK, lists)
+}
+
+func (h *ListHandler) GetList(c *gin.Context) {
+	listId, ok := listIdParam(c)
+	if !ok {
+		return
+	}
+
+	owner, ok := diaryOwner(c, h.DB, h.Logger)
+	if !ok {
+		return
+	}
+
+	list, err := repositories.NewListRepository(h.DB).GetList(owner.ID, listId)
+	if err != nil {
+		h.respondListError(c, err, "Erro ao buscar lista no banco de dados")
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *ListHandler) CreateList(c *gin.Context) {
+	var req listRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Name == nil || strings.TrimSpace(*req.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Nome da lista não fornecido"})
+		return
+	}
+
+	list := &models.List{Name: strings.TrimSpace(*req.Name)}
+	if req.Description != nil {
+		list.Description = strings.TrimSpace(*req.Description)
+	}
+
+	if err := repositories.NewListRepository(h.DB).CreateList(currentUserID(c), list); err != nil {
+		h.Logger.Printf("Erro ao criar lista: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao criar lista no banco de dados"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, list)
+}
+
+func (h *ListHandler) UpdateList(c *gin.Context) {
+	listId, ok := listIdParam(c)
+	if !ok {
+		return
+	}
+
+	var req listRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Corpo da requisição inválido"})
+		return
+	}
+
+	userID := currentUserID(c)
+	repo := repositories.NewListRepository(h.DB)
+	detail, err := repo.GetList(userID, listId)
+	if err != nil {
+		h.respondListError(c, err, "Erro ao buscar lista no banco de dados")
+		return
+	}
+
+	list := detail.List
+	if req.Name != nil {
+		if strings.TrimSpace(*req.Name) == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Nome da lista não pode ser vazio"})
+			return
+		}
+		list.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		list.Description = strings.TrimSpace(*req.Description)
+	}
+
+	if err := repo.UpdateList(userID, &list); err != nil {
+		h.respondListError(c, err, "Erro ao atualizar lista no banco de dados")
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *ListHandler) DeleteList(c *gin.Context) {
+	listId, ok := listIdParam(c)
+	if !ok {
+		return
+	}
+
+	if err := repositories.NewListRepository(h.DB).DeleteList(currentUserID(c), listId); err != nil {
+		h.respondListError(c, err, "Erro ao remover lista do banco de dados")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *ListHandler) AddListItem(c *gin.Context) {
+	listId, ok := listIdParam(c)
+	if !ok {
+		return
+	}
+
+	var req listItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Corpo da requisição inválido"})
+		return
+	}
+	req.TMDBId = strings.TrimSpace(req.TMDBId)
+	if _, err := strconv.Atoi(req.TMDBId); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "tmdbId inválido"})
+		return
+	}
+
+	repo := repositories.NewListRepository(h.DB)
+	if _, err := repo.GetList(currentUserID(c), listId); err != nil {
+		h.respondListError(c, err, "Erro ao buscar lista no banco de dados")
+		return
+	}
+
+	entry := filmEntry{TMDBId: req.TMDBId}
+	if req.Notes != nil {
+		entry.Notes = strings.TrimSpace(*req.Notes)
+	}
+
+	item, err := h.addEntry(c.Request.Context(), repo, listId, entry)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Erro ao adicionar filme à lista"})
+		return
+	}
+	if item == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Filme já está na lista"})
+		return
+	}
+
+	if req.Position != nil {
+		if err := repo.MoveItem(listId, item.TMDBId, *req.Position); err != nil {
+			h.respondListError(c, err, "Erro ao reordenar lista")
+			return
+		}
+		item.Position = max(1, min(*req.Position, item.Position))
+	}
+
+	c.JSON(http.StatusCreated, item)
+}
+
+func (h *ListHandler) UpdateListItem(c *gin.Context) {
+	listId, ok := listIdParam(c)
+	if !ok {
+		return
+	}
+	tmdbId := c.Param("tmdbId")
+
+	var req listItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Corpo da requisição inválido"})
+		return
+	}
+
+	// Os itens são endereçados pela lista; confere antes se ela é do usuário.
+	userID := currentUserID(c)
+	repo := repositories.NewListRepository(h.DB)
+	if _, err := repo.GetList(userID, listId); err != nil {
+		h.respondListError(c, err, "Erro ao buscar lista no banco de dados")
+		return
+	}
+	if req.Notes != nil {
+		if err := repo.UpdateItemNotes(listId, tmdbId, strings.TrimSpace(*req.Notes)); err != nil {
+			h.respondListError(c, err, "Erro ao atualizar item da lista")
+			return
+		}
+	}
+	if req.Position != nil {
+		if err := repo.MoveItem(listId, tmdbId, *req.Position); err != nil {
+			h.respondListError(c, err, "Erro ao reordenar lista")
+			return
+		}
+	}
+
+	list, err := repo.GetList(userID, listId)
+	if err != nil {
+		h.respondListError(c, err, "Erro ao buscar lista no banco de dados")
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *ListHandler) RemoveListItem(c *gin.Context) {
+	listId, ok := listIdParam(c)
+	if !ok {
+		return
+	}
+
+	repo := repositories.NewListRepository(h.DB)
+	if _, err := repo.GetList(currentUserID(c), listId); err != nil {
+		h.respondListError(c, err, "Erro ao buscar lista no banco de dados")
+		return
+	}
+
+	if err := repo.RemoveItem(listId, c.Param("tmdbId")); err != nil {
+		h.respondListError(c, err, "Erro ao remover item da lista")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *ListHandler) ImportList(c *gin.Context) {
+	var req struct {
+		URL  string `json:"url"`
+		Name string `json:"name"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || !isLetterboxdURL(req.URL) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "URL do RSS da lista do Letterboxd inválida"})
+		return
+	}
+
+	feed, err := services.FetchFeed(c.Request.Context(), req.URL)
+	if err != nil {
+		h.Logger.Printf("Erro ao fazer parse do RSS da lista: %v", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Erro ao ler o RSS da lista"})
+		return
+	}
+
+	list := &models.List{
+		Name:        strings.TrimSpace(req.Name),
+		Description: feed.Description,
+		SourceURL:   req.URL,
+	}
+	if list.Name == "" {
+		list.Name = feed.Title
+	}
+
+	userID := currentUserID(c)
+	repo := repositories.NewListRepository(h.DB)
+	if err := repo.CreateList(userID, list); err != nil {
+		h.Logger.Printf("Erro ao criar lista: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao criar lista no banco de dados"})
+		return
+	}
+
+	failed := []string{}
+	for _, item := range feed.Items {
+		entry := filmEntryFromFeedItem(item, "")
+		if _, err := h.addEntry(c.Request.Context(), repo, list.ID, entry); err != nil {
+			failed = append(failed, entry.Title)
+		}
+	}
+
+	detail, err := repo.GetList(userID, list.ID)
+	if err != nil {
+		h.respondListError(c, err, "Erro ao buscar lista no banco de dados")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"list":   detail,
+		"failed": failed,
+	})
+}
+
+func isLetterboxdURL(value string) bool {
+	u, err := url.Parse(value)
+	return err == nil && u.Scheme == "https" && u.Host == "letterboxd.com" && u.User == nil
+}
+
+// addEntry retorna nil quando o filme já está na lista.
+func (h *ListHandler) addEntry(ctx context.Context, repo *repositories.ListRepository, listId int, entry filmEntry) (*models.ListItem, error) {
+	if entry.TMDBId == "" {
+		tmdbId, err := h.TMDBService.SearchMovieID(ctx, entry.Title, entry.Year)
+		if err != nil {
+			h.Logger.Printf("Erro ao identificar %q (%s) no TMDb: %v", entry.Title, entry.Year, err)
+			return nil, err
+		}
+		entry.TMDBId = tmdbId
+	}
+
+	exists, err := repo.ItemExists(listId, entry.TMDBId)
+	if err != nil {
+		h.Logger.Printf("Erro ao verificar item da lista: %v", err)
+		return nil, err
+	}
+	if exists {
+		return nil, nil
+	}
+
+	item := &models.ListItem{
+		TMDBId: entry.TMDBId,
+		Title:  entry.Title,
+		Year:   entry.Year,
+		Notes:  entry.Notes,
+	}
+
+	info, err := h.TMDBService.GetMovieInfo(ctx, entry.TMDBId)
+	if err != nil {
+		h.Logger.Printf("Erro ao buscar informações do TMDb: %v", err)
+		if item.Title == "" {
+			return nil, err
+		}
+	} else {
+		item.Title = info.Title
+		item.PosterPath = info.PosterPath
+		if len(info.ReleaseDate) >= 4 {
+			item.Year = info.ReleaseDate[:4]
+		}
+	}
+
+	if err := repo.AddItem(listId, item); err != nil {
+		h.Logger.Printf("Erro ao adicionar item à lista: %v", err)
+		return nil, err
+	}
+	return item, nil
+}
+
+func (h *ListHandler) GetTags(c *gin.Context) {
+	owner, ok := diaryOwner(c, h.DB, h.Logger)
+	if !ok {
+		return
+	}
+
+	tags, err := repositories.NewTagRepository(h.DB).GetTags(owner.ID)
+	if err != nil {
+		h.Logger.Printf("Erro ao buscar tags: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar tags no banco de dados"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
+func (h *ListHandler) GetMovieTags(c *gin.Context) {
+	owner, ok := diaryOwner(c, h.DB, h.Logger)
+	if !ok {
+		return
+	}
+
+	movie, ok := h.movieParam(c, owner.ID)
+	if !ok {
+		return
+	}
+
+	h.respondMovieTags(c, movie.ID)
+}
+
+func (h *ListHandler) SetMovieTags(c *gin.Context) {
+	movie, ok := h.ownedMovieParam(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Tags []string `json:"tags"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Corpo da requisição inválido"})
+		return
+	}
+
+	names := make([]string, 0, len(req.Tags))
+	for _, tag := range req.Tags {
+		name, ok := normalizeTag(tag)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Tag inválida: " + tag})
+			return
+		}
+		names = append(names, name)
+	}
+
+	if err := repositories.NewTagRepository(h.DB).SetMovieTags(movie.UserID, movie.ID, names); err != nil {
+		h.Logger.Printf("Erro ao salvar tags do filme %s: %v", movie.GUID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao salvar tags no banco de dados"})
+		return
+	}
+
+	h.respondMovieTags(c, movie.ID)
+}
+
+func (h *ListHandler) AddMovieTag(c *gin.Context) {
+	movie, ok := h.ownedMovieParam(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Tag string `json:"tag"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Corpo da requisição inválido"})
+		return
+	}
+	name, ok := normalizeTag(req.Tag)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Tag inválida"})
+		return
+	}
+
+	if err := repositories.NewTagRepository(h.DB).AddMovieTag(movie.UserID, movie.ID, name); err != nil {
+		h.Logger.Printf("Erro ao adicionar tag ao filme %s: %v", movie.GUID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao salvar tag no banco de dados"})
+		return
+	}
+
+	h.respondMovieTags(c, movie.ID)
+}
+
+func (h *ListHandler) RemoveMovieTag(c *gin.Context) {
+	movie, ok := h.ownedMovieParam(c)
+	if !ok {
+		return
+	}
+
+	if err := repositories.NewTagRepository(h.DB).RemoveMovieTag(movie.UserID, movie.ID, c.Param("tag")); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Tag não encontrada no filme"})
+			return
+		}
+		h.Logger.Printf("Erro ao remover tag do filme %s: %v", movie.GUID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao remover tag do banco de dados"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *ListHandler) respondMovieTags(c *gin.Context, movieId int) {
+	tags, err := repositories.NewTagRepository(h.DB).GetMovieTags(movieId)
+	if err != nil {
+		h.Logger.Printf("Erro ao buscar tags do filme: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar tags no banco de dados"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
+// movieParam busca o :guid no diário de userID.
+func (h *ListHandler) movieParam(c *gin.Context, userID int) (*models.Movie, bool) {
+	movie, err := repositories.GetMovieByGUID(h.DB, userID, c.Param("guid"))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Filme não encontrado"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar filme no banco de dados"})
+		}
+		return nil, false
+	}
+	return movie, true
+}
+
+// ownedMovieParam só encontra filmes do diário do usuário logado.
+func (h *ListHandler) ownedMovieParam(c *gin.Context) (*models.Movie, bool) {
+	return h.movieParam(c, currentUserID(c))
+}
+
+func (h *ListHandler) respondListError(c *gin.Context, err error, message string) {
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Lista ou item não encontrado"})
+		return
+	}
+	h.Logger.Printf("%s: %v", message, err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+}
+
+func listIdParam(c *gin.Context) (int, bool) {
+	listId, err := strconv.Atoi(c.Param("id"))
+	if err != nil || listId <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de lista inválido"})
+		return 0, false
+	}
+	return listId, true
+}
+
+func normalizeTag(tag string) (string, bool) {
+	tag = strings.Join(strings.Fields(tag), " ")
+	if tag == "" || len(tag) > maxTagLength || strings.Contains(tag, "/") {
+		return "", false
+	}
+	return tag, true
+}
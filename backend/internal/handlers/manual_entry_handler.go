@@ -0,0 +1,260 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"letterboxd-viewer-backend/internal/models"
+	"letterboxd-viewer-backend/internal/repositories"
+	"letterboxd-viewer-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+const manualGUIDPrefix = "cinedrome-manual-"
+
+// O dia de hoje do usuário pode estar até 14 horas à frente do UTC (UTC+14).
+const maxUTCOffset = 14 * time.Hour
+
+// Entradas do Letterboxd voltariam na próxima sincronização do feed, então
+// só as manuais podem ser alteradas ou removidas por aqui.
+var errImportedEntry = errors.New("entradas importadas do Letterboxd só podem ser alteradas no Letterboxd")
+
+type createMovieRequest struct {
+	TMDBId      string   `json:"tmdbId"`
+	WatchedDate string   `json:"watchedDate"`
+	Rating      *float64 `json:"rating"`
+	Review      string   `json:"review"`
+	Rewatch     bool     `json:"rewatch"`
+}
+
+type updateMovieRequest struct {
+	WatchedDate *string        `json:"watchedDate"`
+	Rating      optionalRating `json:"rating"`
+	Review      *string        `json:"review"`
+	Rewatch     *bool          `json:"rewatch"`
+}
+
+// optionalRating distingue o campo ausente (não altera) de null ou 0, que
+// removem a nota.
+type optionalRating struct {
+	Set   bool
+	Value *float64
+}
+
+func (r *optionalRating) UnmarshalJSON(data []byte) error {
+	r.Set = true
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	var value float64
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	if value != 0 {
+		r.Value = &value
+	}
+	return nil
+}
+
+func (h *MovieHandler) CreateMovie(c *gin.Context) {
+	var req createMovieRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Corpo da requisição inválido"})
+		return
+	}
+
+	req.TMDBId = strings.TrimSpace(req.TMDBId)
+	if _, err := strconv.Atoi(req.TMDBId); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "tmdbId inválido"})
+		return
+	}
+	if err := validateWatchedDate(req.WatchedDate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateRating(req.Rating); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	guid, err := newManualGUID()
+	if err != nil {
+		h.Logger.Printf("Erro ao gerar GUID: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao gerar identificador do filme"})
+		return
+	}
+
+	movie := &models.Movie{
+		TMDBId:       req.TMDBId,
+		WatchedDate:  req.WatchedDate,
+		MemberRating: formatRating(req.Rating),
+		Description:  formatReview(req.Review),
+		Rewatch:      req.Rewatch,
+		GUID:         guid,
+		Source:       models.SourceManual,
+		UserID:       currentUserID(c),
+	}
+
+	if err := h.SyncService.EnrichMovie(c.Request.Context(), movie); err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Erro ao buscar informações do TMDb"})
+		return
+	}
+
+	if err := repositories.InsertMovie(h.DB, movie); err != nil {
+		h.Logger.Printf("Erro ao inserir filme no banco de dados: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao salvar filme no banco de dados"})
+		return
+	}
+	h.Logger.Printf("Filme %s inserido manualmente com sucesso", movie.Title)
+
+	saved, err := repositories.GetMovieByGUID(h.DB, movie.UserID, guid)
+	if err != nil {
+		c.JSON(http.StatusCreated, movie)
+		return
+	}
+	c.JSON(http.StatusCreated, saved)
+}
+
+func (h *MovieHandler) UpdateMovie(c *gin.Context) {
+	guid := c.Param("guid")
+
+	var req updateMovieRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Corpo da requisição inválido"})
+		return
+	}
+
+	movie, ok := h.manualEntryParam(c)
+	if !ok {
+		return
+	}
+
+	if req.WatchedDate != nil {
+		if err := validateWatchedDate(*req.WatchedDate); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		movie.WatchedDate = *req.WatchedDate
+	}
+	if req.Rating.Set {
+		if err := validateRating(req.Rating.Value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		movie.MemberRating = formatRating(req.Rating.Value)
+	}
+	if req.Review != nil {
+		movie.Description = formatReview(*req.Review)
+	}
+	if req.Rewatch != nil {
+		movie.Rewatch = *req.Rewatch
+	}
+
+	if err := repositories.UpdateMovie(h.DB, movie); err != nil {
+		h.Logger.Printf("Erro ao atualizar filme %s: %v", guid, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar filme no banco de dados"})
+		return
+	}
+
+	c.JSON(http.StatusOK, movie)
+}
+
+func (h *MovieHandler) DeleteMovie(c *gin.Context) {
+	guid := c.Param("guid")
+
+	if _, ok := h.manualEntryParam(c); !ok {
+		return
+	}
+
+	if err := repositories.DeleteMovie(h.DB, currentUserID(c), guid); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Filme não encontrado"})
+			return
+		}
+		h.Logger.Printf("Erro ao remover filme %s: %v", guid, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao remover filme do banco de dados"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// manualEntryParam busca a entrada do :guid, que precisa ser do usuário logado
+// e ter sido criada manualmente.
+func (h *MovieHandler) manualEntryParam(c *gin.Context) (*models.Movie, bool) {
+	movie, err := repositories.GetMovieByGUID(h.DB, currentUserID(c), c.Param("guid"))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Filme não encontrado"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar filme no banco de dados"})
+		}
+		return nil, false
+	}
+
+	if movie.Source != models.SourceManual {
+		c.JSON(http.StatusConflict, gin.H{"error": errImportedEntry.Error()})
+		return nil, false
+	}
+
+	return movie, true
+}
+
+func validateWatchedDate(value string) error {
+	if value == "" {
+		return nil
+	}
+
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return errors.New("watchedDate inválido, use o formato AAAA-MM-DD")
+	}
+	latest := time.Now().UTC().Add(maxUTCOffset)
+	if date.After(time.Date(latest.Year(), latest.Month(), latest.Day(), 0, 0, 0, 0, time.UTC)) {
+		return errors.New("watchedDate não pode estar no futuro")
+	}
+	return nil
+}
+
+func validateRating(rating *float64) error {
+	if rating == nil {
+		return nil
+	}
+
+	value := *rating
+	if value < 0.5 || value > 5 || math.Mod(value*2, 1) != 0 {
+		return errors.New("rating deve estar entre 0.5 e 5, em intervalos de meia estrela")
+	}
+	return nil
+}
+
+func formatRating(rating *float64) string {
+	if rating == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*rating, 'f', 1, 64)
+}
+
+func formatReview(review string) string {
+	return services.ReviewHTML(review)
+}
+
+func newManualGUID() (string, error) {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("erro ao gerar bytes aleatórios: %w", err)
+	}
+	return manualGUIDPrefix + hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateRating(t *testing.T) {
+	rating := func(value float64) *float64 { return &value }
+
+	tests := []struct {
+		name   string
+		rating *float64
+		valid  bool
+	}{
+		{name: "sem nota", rating: nil, valid: true},
+		{name: "meia estrela", rating: rating(0.5), valid: true},
+		{name: "nota máxima", rating: rating(5), valid: true},
+		{name: "três e meia", rating: rating(3.5), valid: true},
+		{name: "zero", rating: rating(0), valid: false},
+		{name: "acima do máximo", rating: rating(5.5), valid: false},
+		{name: "fora da meia estrela", rating: rating(3.3), valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateRating(tt.rating); (err == nil) != tt.valid {
+				t.Errorf("validateRating = %v, válido esperado %v", err, tt.valid)
+			}
+		})
+	}
+}
+
+func TestValidateWatchedDate(t *testing.T) {
+	now := time.Now()
+	// O dia mais adiantado do mundo, em UTC+14.
+	ahead := now.UTC().Add(maxUTCOffset)
+
+	tests := []struct {
+		name  string
+		value string
+		valid bool
+	}{
+		{name: "sem data", value: "", valid: true},
+		{name: "ontem", value: now.AddDate(0, 0, -1).Format("2006-01-02"), valid: true},
+		{name: "hoje no servidor", value: now.Format("2006-01-02"), valid: true},
+		{name: "hoje em UTC", value: now.UTC().Format("2006-01-02"), valid: true},
+		{name: "hoje em UTC+14", value: ahead.Format("2006-01-02"), valid: true},
+		{name: "amanhã em UTC+14", value: ahead.AddDate(0, 0, 1).Format("2006-01-02"), valid: false},
+		{name: "formato inválido", value: "01/02/2024", valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateWatchedDate(tt.value); (err == nil) != tt.valid {
+				t.Errorf("validateWatchedDate(%q) = %v, válido esperado %v", tt.value, err, tt.valid)
+			}
+		})
+	}
+}
@@ -1,31 +1,58 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
-	"io/ioutil"
+	"errors"
 	"log"
 	"net/http"
-	"os"
+	"sync"
 
+	"letterboxd-viewer-backend/internal/middleware"
 	"letterboxd-viewer-backend/internal/models"
 	"letterboxd-viewer-backend/internal/repositories"
 	"letterboxd-viewer-backend/internal/services"
 
 	"github.com/gin-gonic/gin"
-	"github.com/mmcdole/gofeed"
 )
 
 type MovieHandler struct {
 	DB          *sql.DB
 	TMDBService *services.TMDBService
+	SyncService *services.SyncService
 	Logger      *log.Logger
+
+	jobsCtx    context.Context
+	cancelJobs context.CancelFunc
+	jobs       sync.WaitGroup
 }
 
-func NewMovieHandler(db *sql.DB, tmdbService *services.TMDBService, logger *log.Logger) *MovieHandler {
+func NewMovieHandler(db *sql.DB, tmdbService *services.TMDBService, syncService *services.SyncService, logger *log.Logger) *MovieHandler {
+	jobsCtx, cancelJobs := context.WithCancel(context.Background())
 	return &MovieHandler{
 		DB:          db,
 		TMDBService: tmdbService,
+		SyncService: syncService,
 		Logger:      logger,
+		jobsCtx:     jobsCtx,
+		cancelJobs:  cancelJobs,
+	}
+}
+
+func (h *MovieHandler) Shutdown(ctx context.Context) error {
+	h.cancelJobs()
+
+	done := make(chan struct{})
+	go func() {
+		h.jobs.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
 
@@ -33,157 +60,64 @@ func (h *MovieHandler) SetupRoutes(router *gin.Engine) {
 	api := router.Group("/api")
 	{
 		api.GET("/rss", h.GetMovies)
+		api.POST("/rss/sync", middleware.RequireAuth, h.SyncFeed)
+		api.POST("/users/:username/sync", middleware.RequireAuth, h.SyncFeed)
 		api.GET("/movie/:guid", h.GetMovieByGUID)
 		api.GET("/movie/:guid/credits", h.GetMovieCredits)
+		api.POST("/movies", middleware.RequireAuth, h.CreateMovie)
+		api.PATCH("/movies/:guid", middleware.RequireAuth, h.UpdateMovie)
+		api.DELETE("/movies/:guid", middleware.RequireAuth, h.DeleteMovie)
 	}
 }
 
 func (h *MovieHandler) GetMovies(c *gin.Context) {
-
-	movies, err := h.processRSSFeed()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	owner, ok := diaryOwner(c, h.DB, h.Logger)
+	if !ok {
 		return
 	}
 
-	c.JSON(http.StatusOK, movies)
-}
-
-func (h *MovieHandler) processRSSFeed() ([]models.Movie, error) {
-
-	// Adicionar o path em questão no .env, apontando para o arquivo .rss gerado pelo próprio LEtterboxd
-	rssPath := os.Getenv("RSS_FILE_PATH")
-	content, err := ioutil.ReadFile(rssPath)
-	if err != nil {
-		h.Logger.Printf("Erro ao ler o arquivo RSS: %v", err)
-		return nil, err
-	}
-
-	fp := gofeed.NewParser()
-	feed, err := fp.ParseString(string(content))
+	movies, err := h.getAllMovies(owner.ID)
 	if err != nil {
-		h.Logger.Printf("Erro ao fazer parse do RSS: %v", err)
-		return nil, err
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar filmes no banco de dados"})
+		return
 	}
 
-	h.processFeedItems(feed)
-
-	return h.getAllMovies()
+	c.JSON(http.StatusOK, movies)
 }
 
-func (h *MovieHandler) processFeedItems(feed *gofeed.Feed) {
-	for _, item := range feed.Items {
-		guid := item.GUID
-		exists, err := repositories.CheckMovieExists(h.DB, guid)
-		if err != nil {
-			h.Logger.Printf("Erro ao verificar filme no banco de dados: %v", err)
-			continue
-		}
-		if exists {
-			continue
-		}
-
-		movie := h.createMovieFromFeedItem(item)
-		if movie == nil {
-			continue
-		}
-
-		err = repositories.InsertMovie(h.DB, movie)
-		if err != nil {
-			h.Logger.Printf("Erro ao inserir filme no banco de dados: %v", err)
-			continue
-		}
-		h.Logger.Printf("Filme %s inserido com sucesso", movie.Title)
+// SyncFeed importa o RSS do diário do usuário logado. Antes a sincronização
+// acontecia a cada GET /rss; agora é uma escrita explícita e exige login.
+// Pela rota /users/:username/sync, cada um só sincroniza o próprio diário.
+func (h *MovieHandler) SyncFeed(c *gin.Context) {
+	user := middleware.CurrentUser(c)
+	if username := c.Param("username"); username != "" && services.NormalizeUsername(username) != user.Username {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Só é possível sincronizar o próprio diário"})
+		return
 	}
-}
-
-func (h *MovieHandler) createMovieFromFeedItem(item *gofeed.Item) *models.Movie {
-	guid := item.GUID
 
-	var watchedDate, memberRating string
-	if ext, ok := item.Extensions["letterboxd"]; ok {
-		if len(ext["watchedDate"]) > 0 {
-			watchedDate = ext["watchedDate"][0].Value
-		} else {
-			h.Logger.Printf("watchedDate não encontrado para o item com GUID: %s", guid)
-		}
+	h.jobs.Add(1)
+	defer h.jobs.Done()
 
-		if len(ext["memberRating"]) > 0 {
-			memberRating = ext["memberRating"][0].Value
-		} else {
-			h.Logger.Printf("memberRating não encontrado para o item com GUID: %s", guid)
-		}
-	}
-
-	movie := &models.Movie{
-		Title:        item.Extensions["letterboxd"]["filmTitle"][0].Value,
-		Year:         item.Extensions["letterboxd"]["filmYear"][0].Value,
-		WatchedDate:  watchedDate,
-		MemberRating: memberRating,
-		Description:  item.Description,
-		GUID:         guid,
+	result, err := h.SyncService.SyncUser(h.jobsCtx, user, "")
+	if errors.Is(err, services.ErrNoFeedSource) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
-
-	if tmdb, ok := item.Extensions["tmdb"]; ok {
-		if movieId, ok := tmdb["movieId"]; ok && len(movieId) > 0 {
-			movie.TMDBId = movieId[0].Value
-
-			tmdbInfo, err := h.TMDBService.GetMovieInfo(movie.TMDBId)
-			if err != nil {
-				h.Logger.Printf("Erro ao buscar informações do TMDb: %v", err)
-			} else {
-
-				h.updateMovieWithTMDBInfo(movie, tmdbInfo)
-			}
-		}
+	if err != nil {
+		h.Logger.Printf("Sincronização interrompida: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
 	}
 
-	return movie
+	c.JSON(http.StatusOK, result)
 }
 
-func (h *MovieHandler) updateMovieWithTMDBInfo(movie *models.Movie, tmdbInfo *models.Movie) {
-	movie.Plot = tmdbInfo.Plot
-	movie.Genre = tmdbInfo.Genre
-	movie.Director = tmdbInfo.Director
-	movie.IMDBRating = tmdbInfo.IMDBRating
-	movie.Runtime = tmdbInfo.Runtime
-	movie.ReleaseDate = tmdbInfo.ReleaseDate
-	movie.Budget = tmdbInfo.Budget
-	movie.Revenue = tmdbInfo.Revenue
-	movie.Tagline = tmdbInfo.Tagline
-	movie.Status = tmdbInfo.Status
-	movie.OriginalLanguage = tmdbInfo.OriginalLanguage
-	movie.ProductionCompanies = tmdbInfo.ProductionCompanies
-	movie.SpokenLanguages = tmdbInfo.SpokenLanguages
-	movie.PosterPath = tmdbInfo.PosterPath
-	movie.BackdropPath = tmdbInfo.BackdropPath
-	movie.Homepage = tmdbInfo.Homepage
-}
-
-func (h *MovieHandler) getAllMovies() ([]models.Movie, error) {
-	var allMovies []models.Movie
-	rows, err := h.DB.Query("SELECT * FROM public.filmes")
+func (h *MovieHandler) getAllMovies(userID int) ([]models.Movie, error) {
+	allMovies, err := repositories.GetAllMovies(h.DB, userID)
 	if err != nil {
 		h.Logger.Printf("Erro ao buscar filmes do banco de dados: %v", err)
 		return nil, err
 	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var movie models.Movie
-		err = rows.Scan(
-			&movie.ID, &movie.Title, &movie.Year, &movie.WatchedDate, &movie.MemberRating,
-			&movie.Description, &movie.IMDBRating, &movie.Genre, &movie.Plot, &movie.Director,
-			&movie.TMDBId, &movie.Runtime, &movie.ReleaseDate, &movie.Budget, &movie.Revenue,
-			&movie.Tagline, &movie.Status, &movie.OriginalLanguage, &movie.ProductionCompanies,
-			&movie.SpokenLanguages, &movie.PosterPath, &movie.BackdropPath, &movie.Homepage, &movie.GUID,
-		)
-		if err != nil {
-			h.Logger.Printf("Erro ao ler o filme do banco de dados: %v", err)
-			continue
-		}
-		allMovies = append(allMovies, movie)
-	}
 
 	h.Logger.Printf("Total de filmes no banco de dados: %d", len(allMovies))
 	return allMovies, nil
@@ -196,7 +130,12 @@ func (h *MovieHandler) GetMovieByGUID(c *gin.Context) {
 		return
 	}
 
-	movie, err := repositories.GetMovieByGUID(h.DB, guid)
+	owner, ok := diaryOwner(c, h.DB, h.Logger)
+	if !ok {
+		return
+	}
+
+	movie, err := repositories.GetMovieByGUID(h.DB, owner.ID, guid)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Filme não encontrado"})
@@ -216,7 +155,12 @@ func (h *MovieHandler) GetMovieCredits(c *gin.Context) {
 		return
 	}
 
-	movie, err := repositories.GetMovieByGUID(h.DB, guid)
+	owner, ok := diaryOwner(c, h.DB, h.Logger)
+	if !ok {
+		return
+	}
+
+	movie, err := repositories.GetMovieByGUID(h.DB, owner.ID, guid)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Filme não encontrado"})
@@ -231,20 +175,27 @@ func (h *MovieHandler) GetMovieCredits(c *gin.Context) {
 		return
 	}
 
-	credits, err := h.TMDBService.GetMovieCredits(movie.TMDBId)
+	credits, err := repositories.GetCredits(h.DB, movie.TMDBId)
+	if err == nil {
+		c.JSON(http.StatusOK, credits)
+		return
+	}
+	if err != sql.ErrNoRows {
+		h.Logger.Printf("Erro ao buscar créditos no banco de dados: %v", err)
+	}
+
+	// Filmes importados antes do armazenamento de créditos ainda não os possuem
+	// no banco; buscamos no TMDb uma única vez e guardamos o resultado.
+	credits, err = h.TMDBService.GetMovieCredits(c.Request.Context(), movie.TMDBId)
 	if err != nil {
 		h.Logger.Printf("Erro ao buscar créditos do TMDb: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar créditos do TMDb"})
 		return
 	}
 
-	c.JSON(http.StatusOK, credits)
-}
-
-func SetupRoutes(router *gin.Engine, db *sql.DB) {
-	logger := log.New(os.Stdout, "[MovieHandler] ", log.LstdFlags)
-	tmdbService := services.NewTMDBService(os.Getenv("TMDB_ACCESS_TOKEN"))
+	if err := repositories.SaveCredits(h.DB, movie.TMDBId, credits); err != nil {
+		h.Logger.Printf("Erro ao salvar créditos no banco de dados: %v", err)
+	}
 
-	handler := NewMovieHandler(db, tmdbService, logger)
-	handler.SetupRoutes(router)
+	c.JSON(http.StatusOK, credits)
 }
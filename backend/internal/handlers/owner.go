@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+
+	"letterboxd-viewer-backend/internal/middleware"
+	"letterboxd-viewer-backend/internal/models"
+	"letterboxd-viewer-backend/internal/repositories"
+	"letterboxd-viewer-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// diaryOwner decide de quem é o diário consultado: o usuário da rota
+// (/api/users/:username/...) ou do parâmetro ?user=, o usuário logado ou,
+// para visitantes anônimos, o dono da instância. Antes do primeiro cadastro
+// devolve um usuário vazio, e as consultas simplesmente não encontram nada.
+func diaryOwner(c *gin.Context, db *sql.DB, logger *log.Logger) (*models.User, bool) {
+	username := c.Param("username")
+	if username == "" {
+		username = c.Query("user")
+	}
+
+	var user *models.User
+	var err error
+	switch {
+	case username != "":
+		user, err = repositories.GetUserByUsername(db, services.NormalizeUsername(username))
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Usuário não encontrado"})
+			return nil, false
+		}
+	case middleware.CurrentUser(c) != nil:
+		return middleware.CurrentUser(c), true
+	default:
+		user, err = repositories.GetDefaultUser(db)
+		if err == sql.ErrNoRows {
+			return &models.User{}, true
+		}
+	}
+
+	if err != nil {
+		logger.Printf("Erro ao buscar usuário: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar usuário no banco de dados"})
+		return nil, false
+	}
+	return user, true
+}
+
+// currentUserID é usado nas rotas de escrita, sempre protegidas por RequireAuth.
+func currentUserID(c *gin.Context) int {
+	return middleware.CurrentUser(c).ID
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"letterboxd-viewer-backend/internal/models"
+	"letterboxd-viewer-backend/internal/repositories"
+	"letterboxd-viewer-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	personCacheTTL       = 7 * 24 * time.Hour
+	defaultTopPeopleSize = 20
+	maxTopPeopleSize     = 100
+)
+
+type PeopleHandler struct {
+	DB          *sql.DB
+	TMDBService *services.TMDBService
+	Logger      *log.Logger
+}
+
+func NewPeopleHandler(db *sql.DB, tmdbService *services.TMDBService, logger *log.Logger) *PeopleHandler {
+	return &PeopleHandler{
+		DB:          db,
+		TMDBService: tmdbService,
+		Logger:      logger,
+	}
+}
+
+func (h *PeopleHandler) SetupRoutes(router *gin.Engine) {
+	api := router.Group("/api")
+	{
+		api.GET("/people/top", h.GetTopPeople)
+		api.GET("/people/:tmdbPersonId", h.GetPerson)
+	}
+}
+
+func (h *PeopleHandler) GetPerson(c *gin.Context) {
+	personId, err := strconv.Atoi(c.Param("tmdbPersonId"))
+	if err != nil || personId <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de pessoa inválido"})
+		return
+	}
+
+	owner, ok := diaryOwner(c, h.DB, h.Logger)
+	if !ok {
+		return
+	}
+
+	films, err := repositories.GetFilmography(h.DB, owner.ID, personId)
+	if err != nil {
+		h.Logger.Printf("Erro ao buscar filmografia da pessoa %d: %v", personId, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar filmografia no banco de dados"})
+		return
+	}
+
+	person, err := h.getPerson(c.Request.Context(), personId)
+	if err != nil {
+		h.Logger.Printf("Erro ao buscar pessoa %d no TMDb: %v", personId, err)
+		person, err = repositories.GetPerson(h.DB, personId)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Pessoa não encontrada"})
+			} else {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar pessoa no banco de dados"})
+			}
+			return
+		}
+	}
+
+	page := &models.PersonPage{
+		Person:    person,
+		FilmCount: len(films),
+		Films:     films,
+	}
+
+	var sum float64
+	var rated int
+	for _, film := range films {
+		rating, err := film.Movie.ParsedMemberRating()
+		if err != nil || rating == 0 {
+			continue
+		}
+		sum += rating
+		rated++
+	}
+	if rated > 0 {
+		average := sum / float64(rated)
+		page.AverageRating = &average
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func (h *PeopleHandler) getPerson(ctx context.Context, personId int) (*models.Person, error) {
+	key := fmt.Sprintf("person:%d", personId)
+
+	payload, found, err := repositories.GetCached(h.DB, key, personCacheTTL)
+	if err != nil {
+		h.Logger.Printf("Erro ao ler cache da pessoa %d: %v", personId, err)
+	}
+	if found {
+		var person models.Person
+		if err := json.Unmarshal(payload, &person); err == nil {
+			return &person, nil
+		}
+	}
+
+	person, err := h.TMDBService.GetPerson(ctx, personId)
+	if err != nil {
+		return nil, err
+	}
+
+	if payload, err := json.Marshal(person); err == nil {
+		if err := repositories.SetCached(h.DB, key, payload); err != nil {
+			h.Logger.Printf("Erro ao gravar cache da pessoa %d: %v", personId, err)
+		}
+	}
+
+	return person, nil
+}
+
+func (h *PeopleHandler) GetTopPeople(c *gin.Context) {
+	role := c.DefaultQuery("role", "director")
+	if !repositories.IsValidPersonRole(role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parâmetro role inválido"})
+		return
+	}
+
+	limit := defaultTopPeopleSize
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Parâmetro limit inválido"})
+			return
+		}
+		limit = min(parsed, maxTopPeopleSize)
+	}
+
+	owner, ok := diaryOwner(c, h.DB, h.Logger)
+	if !ok {
+		return
+	}
+
+	rankings, err := repositories.GetTopPeople(h.DB, owner.ID, role, limit)
+	if err != nil {
+		h.Logger.Printf("Erro ao buscar ranking de pessoas: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar ranking de pessoas"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"role":   role,
+		"people": rankings,
+	})
+}
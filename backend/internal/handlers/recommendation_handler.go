@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"letterboxd-viewer-backend/internal/repositories"
+	"letterboxd-viewer-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultRecommendationLimit = 20
+	maxRecommendationLimit     = 50
+)
+
+type RecommendationHandler struct {
+	DB                    *sql.DB
+	RecommendationService *services.RecommendationService
+	Logger                *log.Logger
+}
+
+func NewRecommendationHandler(db *sql.DB, recommendationService *services.RecommendationService, logger *log.Logger) *RecommendationHandler {
+	return &RecommendationHandler{
+		DB:                    db,
+		RecommendationService: recommendationService,
+		Logger:                logger,
+	}
+}
+
+func (h *RecommendationHandler) SetupRoutes(router *gin.Engine) {
+	api := router.Group("/api")
+	{
+		api.GET("/recommendations", h.GetRecommendations)
+		api.GET("/movie/:guid/similar", h.GetSimilarMovies)
+	}
+}
+
+// GetRecommendations sugere filmes que o dono do diário ainda não registrou,
+// a partir das notas dos críticos que pensam parecido com ele. Aceita os
+// filtros genre, decade (ex.: 1990 ou 1990s), minRuntime e maxRuntime.
+func (h *RecommendationHandler) GetRecommendations(c *gin.Context) {
+	filter := services.RecommendationFilter{
+		Genre: strings.TrimSpace(c.Query("genre")),
+		Limit: defaultRecommendationLimit,
+	}
+
+	if value := strings.TrimSuffix(c.Query("decade"), "s"); value != "" {
+		decade, err := strconv.Atoi(value)
+		if err != nil || decade <= 0 || decade%10 != 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Parâmetro decade inválido"})
+			return
+		}
+		filter.Decade = decade
+	}
+
+	for param, target := range map[string]*int{
+		"minRuntime": &filter.MinRuntime,
+		"maxRuntime": &filter.MaxRuntime,
+		"limit":      &filter.Limit,
+	} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Parâmetro " + param + " inválido"})
+			return
+		}
+		*target = parsed
+	}
+	filter.Limit = min(filter.Limit, maxRecommendationLimit)
+
+	if filter.MaxRuntime > 0 && filter.MinRuntime > filter.MaxRuntime {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "minRuntime maior que maxRuntime"})
+		return
+	}
+
+	owner, ok := diaryOwner(c, h.DB, h.Logger)
+	if !ok {
+		return
+	}
+
+	recommendations, err := h.RecommendationService.Recommend(owner.ID, filter)
+	if err != nil {
+		h.Logger.Printf("Erro ao gerar recomendações: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao gerar recomendações"})
+		return
+	}
+
+	c.JSON(http.StatusOK, recommendations)
+}
+
+// GetSimilarMovies lista filmes parecidos com o da entrada, marcando os que o
+// dono da entrada já viu. Com tmdb=false, usa só os metadados guardados.
+func (h *RecommendationHandler) GetSimilarMovies(c *gin.Context) {
+	limit := defaultRecommendationLimit
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Parâmetro limit inválido"})
+			return
+		}
+		limit = min(parsed, maxRecommendationLimit)
+	}
+
+	useTMDb := true
+	if value := c.Query("tmdb"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Parâmetro tmdb inválido"})
+			return
+		}
+		useTMDb = parsed
+	}
+
+	owner, ok := diaryOwner(c, h.DB, h.Logger)
+	if !ok {
+		return
+	}
+
+	movie, err := repositories.GetMovieByGUID(h.DB, owner.ID, c.Param("guid"))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Filme não encontrado"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar filme no banco de dados"})
+		}
+		return
+	}
+
+	if movie.TMDBId == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID do TMDB não disponível para o filme especificado"})
+		return
+	}
+
+	similar, err := h.RecommendationService.SimilarMovies(c.Request.Context(), movie.UserID, movie, useTMDb, limit)
+	if err != nil {
+		h.Logger.Printf("Erro ao buscar filmes parecidos com %s: %v", movie.GUID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar filmes parecidos"})
+		return
+	}
+
+	c.JSON(http.StatusOK, similar)
+}
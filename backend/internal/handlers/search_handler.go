@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"letterboxd-viewer-backend/internal/repositories"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+type SearchHandler struct {
+	DB     *sql.DB
+	Logger *log.Logger
+}
+
+func NewSearchHandler(db *sql.DB, logger *log.Logger) *SearchHandler {
+	return &SearchHandler{
+		DB:     db,
+		Logger: logger,
+	}
+}
+
+func (h *SearchHandler) SetupRoutes(router *gin.Engine) {
+	api := router.Group("/api")
+	{
+		api.GET("/search", h.Search)
+	}
+}
+
+func (h *SearchHandler) Search(c *gin.Context) {
+	term := strings.TrimSpace(c.Query("q"))
+	if term == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parâmetro q não fornecido"})
+		return
+	}
+
+	limit := defaultSearchLimit
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Parâmetro limit inválido"})
+			return
+		}
+		limit = min(parsed, maxSearchLimit)
+	}
+
+	owner, ok := diaryOwner(c, h.DB, h.Logger)
+	if !ok {
+		return
+	}
+
+	results, err := repositories.SearchMovies(h.DB, owner.ID, term, limit)
+	if err != nil {
+		h.Logger.Printf("Erro ao buscar filmes: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar filmes no banco de dados"})
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"letterboxd-viewer-backend/internal/repositories"
+	"letterboxd-viewer-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultRollingWindow = 3
+	maxRollingWindow     = 52
+)
+
+var timeSeriesBuckets = map[string]bool{
+	"week":  true,
+	"month": true,
+	"year":  true,
+}
+
+type StatsHandler struct {
+	DB     *sql.DB
+	Logger *log.Logger
+}
+
+func NewStatsHandler(db *sql.DB, logger *log.Logger) *StatsHandler {
+	return &StatsHandler{
+		DB:     db,
+		Logger: logger,
+	}
+}
+
+func (h *StatsHandler) SetupRoutes(router *gin.Engine) {
+	api := router.Group("/api")
+	{
+		api.GET("/stats", h.GetStats)
+		api.GET("/stats/ratings/timeseries", h.GetRatingTimeSeries)
+		api.GET("/stats/calendar", h.GetCalendar)
+		api.GET("/years/:year/review", h.GetYearReview)
+	}
+}
+
+func (h *StatsHandler) GetStats(c *gin.Context) {
+	dateRange, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+
+	owner, ok := diaryOwner(c, h.DB, h.Logger)
+	if !ok {
+		return
+	}
+
+	stats, err := repositories.GetStats(h.DB, owner.ID, dateRange)
+	if err != nil {
+		h.Logger.Printf("Erro ao calcular estatísticas: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao calcular estatísticas"})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+func (h *StatsHandler) GetRatingTimeSeries(c *gin.Context) {
+	dateRange, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+
+	bucket := c.DefaultQuery("bucket", "month")
+	if !timeSeriesBuckets[bucket] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parâmetro bucket inválido, use week, month ou year"})
+		return
+	}
+
+	window := defaultRollingWindow
+	if value := c.Query("window"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 || parsed > maxRollingWindow {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Parâmetro window inválido"})
+			return
+		}
+		window = parsed
+	}
+
+	owner, ok := diaryOwner(c, h.DB, h.Logger)
+	if !ok {
+		return
+	}
+
+	points, err := repositories.GetRatingTimeSeries(h.DB, owner.ID, dateRange, bucket, window)
+	if err != nil {
+		h.Logger.Printf("Erro ao calcular série temporal de notas: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao calcular série temporal de notas"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"bucket": bucket,
+		"window": window,
+		"points": points,
+	})
+}
+
+func (h *StatsHandler) GetYearReview(c *gin.Context) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil || year < 1870 || year > time.Now().Year() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ano inválido"})
+		return
+	}
+
+	owner, ok := diaryOwner(c, h.DB, h.Logger)
+	if !ok {
+		return
+	}
+
+	review, err := repositories.GetYearReview(h.DB, owner.ID, year)
+	if err != nil {
+		h.Logger.Printf("Erro ao gerar retrospectiva de %d: %v", year, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao gerar retrospectiva do ano"})
+		return
+	}
+
+	c.JSON(http.StatusOK, review)
+}
+
+func (h *StatsHandler) GetCalendar(c *gin.Context) {
+	dateRange, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+	if dateRange.To != "" {
+		to, _ = time.Parse("2006-01-02", dateRange.To)
+	}
+	from := to.AddDate(-1, 0, 1)
+	if dateRange.From != "" {
+		from, _ = time.Parse("2006-01-02", dateRange.From)
+	}
+	if to.Before(from) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parâmetro to deve ser posterior a from"})
+		return
+	}
+	dateRange.From = from.Format("2006-01-02")
+	dateRange.To = to.Format("2006-01-02")
+
+	owner, ok := diaryOwner(c, h.DB, h.Logger)
+	if !ok {
+		return
+	}
+
+	days, err := repositories.GetCalendarDays(h.DB, owner.ID, dateRange)
+	if err != nil {
+		h.Logger.Printf("Erro ao buscar atividade diária: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar atividade diária"})
+		return
+	}
+
+	c.JSON(http.StatusOK, services.BuildCalendar(days, from, to))
+}
+
+func parseDateRange(c *gin.Context) (repositories.DateRange, bool) {
+	dateRange := repositories.DateRange{
+		From: c.Query("from"),
+		To:   c.Query("to"),
+	}
+
+	var from, to time.Time
+	var err error
+	if dateRange.From != "" {
+		if from, err = time.Parse("2006-01-02", dateRange.From); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Parâmetro from inválido, use o formato AAAA-MM-DD"})
+			return dateRange, false
+		}
+	}
+	if dateRange.To != "" {
+		if to, err = time.Parse("2006-01-02", dateRange.To); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Parâmetro to inválido, use o formato AAAA-MM-DD"})
+			return dateRange, false
+		}
+	}
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parâmetro to deve ser posterior a from"})
+		return dateRange, false
+	}
+
+	return dateRange, true
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+
+	"letterboxd-viewer-backend/internal/repositories"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UserHandler struct {
+	DB     *sql.DB
+	Logger *log.Logger
+}
+
+func NewUserHandler(db *sql.DB, logger *log.Logger) *UserHandler {
+	return &UserHandler{
+		DB:     db,
+		Logger: logger,
+	}
+}
+
+func (h *UserHandler) SetupRoutes(router *gin.Engine) {
+	api := router.Group("/api")
+	{
+		api.GET("/users", h.GetUsers)
+		api.GET("/users/:username", h.GetUser)
+	}
+}
+
+func (h *UserHandler) GetUsers(c *gin.Context) {
+	users, err := repositories.GetUsers(h.DB)
+	if err != nil {
+		h.Logger.Printf("Erro ao buscar usuários: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar usuários no banco de dados"})
+		return
+	}
+
+	c.JSON(http.StatusOK, users)
+}
+
+func (h *UserHandler) GetUser(c *gin.Context) {
+	user, ok := diaryOwner(c, h.DB, h.Logger)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
@@ -0,0 +1,323 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"letterboxd-viewer-backend/internal/middleware"
+	"letterboxd-viewer-backend/internal/models"
+	"letterboxd-viewer-backend/internal/repositories"
+	"letterboxd-viewer-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mmcdole/gofeed"
+)
+
+type WatchlistHandler struct {
+	DB          *sql.DB
+	TMDBService *services.TMDBService
+	Logger      *log.Logger
+	FeedPath    string
+}
+
+// Cada linha do CSV custa algumas chamadas ao TMDb dentro da requisição;
+// arquivos maiores são importados em lotes com ?offset=.
+const maxWatchlistImportRows = 100
+
+type watchlistImportResult struct {
+	Added   int      `json:"added"`
+	Skipped int      `json:"skipped"`
+	Failed  []string `json:"failed"`
+	// NextOffset é o offset do próximo lote, ou nil se o arquivo acabou.
+	NextOffset *int `json:"nextOffset,omitempty"`
+}
+
+// feedPath é o RSS da watchlist exportado pelo Letterboxd, usado pelo
+// /watchlist/sync e válido só para o dono da instância.
+func NewWatchlistHandler(db *sql.DB, tmdbService *services.TMDBService, feedPath string, logger *log.Logger) *WatchlistHandler {
+	return &WatchlistHandler{
+		DB:          db,
+		TMDBService: tmdbService,
+		Logger:      logger,
+		FeedPath:    feedPath,
+	}
+}
+
+func (h *WatchlistHandler) SetupRoutes(router *gin.Engine) {
+	api := router.Group("/api")
+	{
+		api.GET("/watchlist", h.GetWatchlist)
+		api.POST("/watchlist", middleware.RequireAuth, h.AddToWatchlist)
+		api.DELETE("/watchlist/:tmdbId", middleware.RequireAuth, h.RemoveFromWatchlist)
+		api.POST("/watchlist/import", middleware.RequireAuth, h.ImportCSV)
+		api.POST("/watchlist/sync", middleware.RequireAuth, h.SyncFeed)
+	}
+}
+
+func (h *WatchlistHandler) GetWatchlist(c *gin.Context) {
+	owner, ok := diaryOwner(c, h.DB, h.Logger)
+	if !ok {
+		return
+	}
+
+	items, err := repositories.GetWatchlist(h.DB, owner.ID)
+	if err != nil {
+		h.Logger.Printf("Erro ao buscar watchlist: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar watchlist no banco de dados"})
+		return
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
+func (h *WatchlistHandler) AddToWatchlist(c *gin.Context) {
+	var req struct {
+		TMDBId string `json:"tmdbId"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Corpo da requisição inválido"})
+		return
+	}
+
+	req.TMDBId = strings.TrimSpace(req.TMDBId)
+	if _, err := strconv.Atoi(req.TMDBId); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "tmdbId inválido"})
+		return
+	}
+
+	added, err := h.addEntry(c.Request.Context(), currentUserID(c), filmEntry{TMDBId: req.TMDBId, Source: models.WatchlistSourceManual})
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Erro ao adicionar filme à watchlist"})
+		return
+	}
+	if !added {
+		c.JSON(http.StatusConflict, gin.H{"error": "Filme já está na watchlist ou já foi assistido"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"tmdbId": req.TMDBId})
+}
+
+func (h *WatchlistHandler) RemoveFromWatchlist(c *gin.Context) {
+	tmdbId := c.Param("tmdbId")
+
+	if err := repositories.RemoveWatchlistItem(h.DB, currentUserID(c), tmdbId); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Filme não encontrado na watchlist"})
+			return
+		}
+		h.Logger.Printf("Erro ao remover filme %s da watchlist: %v", tmdbId, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao remover filme da watchlist"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *WatchlistHandler) ImportCSV(c *gin.Context) {
+	file, _, err := c.Request.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Arquivo watchlist.csv não enviado no campo file"})
+		return
+	}
+	defer file.Close()
+
+	offset := 0
+	if value := c.Query("offset"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Parâmetro offset inválido"})
+			return
+		}
+		offset = parsed
+	}
+
+	entries, err := parseWatchlistCSV(file)
+	if err != nil {
+		h.Logger.Printf("Erro ao ler watchlist.csv: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Arquivo CSV inválido"})
+		return
+	}
+
+	entries = entries[min(offset, len(entries)):]
+	var nextOffset *int
+	if len(entries) > maxWatchlistImportRows {
+		entries = entries[:maxWatchlistImportRows]
+		next := offset + maxWatchlistImportRows
+		nextOffset = &next
+	}
+
+	result := h.importEntries(c.Request.Context(), currentUserID(c), entries)
+	result.NextOffset = nextOffset
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *WatchlistHandler) SyncFeed(c *gin.Context) {
+	if h.FeedPath == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "WATCHLIST_RSS_FILE_PATH não configurado"})
+		return
+	}
+
+	owner, err := repositories.GetDefaultUser(h.DB)
+	if err != nil {
+		h.Logger.Printf("Erro ao buscar dono da instância: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar usuário no banco de dados"})
+		return
+	}
+	if owner.ID != currentUserID(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "WATCHLIST_RSS_FILE_PATH pertence ao dono da instância"})
+		return
+	}
+
+	file, err := os.Open(h.FeedPath)
+	if err != nil {
+		h.Logger.Printf("Erro ao ler o arquivo RSS da watchlist: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao ler o arquivo RSS da watchlist"})
+		return
+	}
+	defer file.Close()
+
+	feed, err := gofeed.NewParser().Parse(file)
+	if err != nil {
+		h.Logger.Printf("Erro ao fazer parse do RSS da watchlist: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao fazer parse do RSS da watchlist"})
+		return
+	}
+
+	entries := make([]filmEntry, 0, len(feed.Items))
+	for _, item := range feed.Items {
+		entries = append(entries, filmEntryFromFeedItem(item, models.WatchlistSourceRSS))
+	}
+
+	c.JSON(http.StatusOK, h.importEntries(c.Request.Context(), owner.ID, entries))
+}
+
+func (h *WatchlistHandler) importEntries(ctx context.Context, userID int, entries []filmEntry) watchlistImportResult {
+	result := watchlistImportResult{Failed: []string{}}
+
+	for _, entry := range entries {
+		added, err := h.addEntry(ctx, userID, entry)
+		if err != nil {
+			result.Failed = append(result.Failed, entry.Title)
+			continue
+		}
+		if added {
+			result.Added++
+		} else {
+			result.Skipped++
+		}
+	}
+
+	h.Logger.Printf("Watchlist importada: %d adicionados, %d ignorados, %d falhas",
+		result.Added, result.Skipped, len(result.Failed))
+	return result
+}
+
+func (h *WatchlistHandler) addEntry(ctx context.Context, userID int, entry filmEntry) (bool, error) {
+	if entry.TMDBId == "" {
+		tmdbId, err := h.TMDBService.SearchMovieID(ctx, entry.Title, entry.Year)
+		if err != nil {
+			h.Logger.Printf("Erro ao identificar %q (%s) no TMDb: %v", entry.Title, entry.Year, err)
+			return false, err
+		}
+		entry.TMDBId = tmdbId
+	}
+
+	exists, err := repositories.WatchlistItemExists(h.DB, userID, entry.TMDBId)
+	if err != nil {
+		h.Logger.Printf("Erro ao verificar watchlist: %v", err)
+		return false, err
+	}
+	if exists {
+		return false, nil
+	}
+
+	item := &models.WatchlistItem{
+		TMDBId:        entry.TMDBId,
+		Title:         entry.Title,
+		Year:          entry.Year,
+		LetterboxdURI: entry.LetterboxdURI,
+		Source:        entry.Source,
+		AddedDate:     entry.AddedDate,
+	}
+
+	info, err := h.TMDBService.GetMovieInfo(ctx, entry.TMDBId)
+	if err != nil {
+		h.Logger.Printf("Erro ao buscar informações do TMDb: %v", err)
+		if item.Title == "" {
+			return false, err
+		}
+	} else {
+		item.Title = info.Title
+		item.OriginalTitle = info.OriginalTitle
+		item.Genre = info.Genre
+		item.Plot = info.Plot
+		item.Runtime = info.Runtime
+		item.PosterPath = info.PosterPath
+		if len(info.ReleaseDate) >= 4 {
+			item.Year = info.ReleaseDate[:4]
+		}
+	}
+
+	added, err := repositories.AddWatchlistItem(h.DB, userID, item)
+	if err != nil {
+		h.Logger.Printf("Erro ao adicionar filme à watchlist: %v", err)
+		return false, err
+	}
+	return added, nil
+}
+
+// Formato do export do Letterboxd: Date,Name,Year,Letterboxd URI
+func parseWatchlistCSV(r io.Reader) ([]filmEntry, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))] = i
+	}
+	if _, ok := columns["Name"]; !ok {
+		return nil, errors.New("coluna Name ausente no CSV")
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var entries []filmEntry
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, filmEntry{
+			Title:         field(record, "Name"),
+			Year:          field(record, "Year"),
+			AddedDate:     field(record, "Date"),
+			LetterboxdURI: field(record, "Letterboxd URI"),
+			Source:        models.WatchlistSourceCSV,
+		})
+	}
+
+	return entries, nil
+}
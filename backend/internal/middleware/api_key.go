@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"letterboxd-viewer-backend/internal/models"
+	"letterboxd-viewer-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+const apiKeyContextKey = "auth.apiKey"
+
+// AuthenticateAPIKey aceita chaves pessoais no cabeçalho Authorization:
+// Bearer, no lugar do cookie de sessão. Diferente do cookie, uma chave
+// inválida recusa a requisição: o script deve saber que a chave não vale mais.
+func AuthenticateAPIKey(keys *services.APIKeyService, logger *log.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		if header == "" {
+			c.Next()
+			return
+		}
+
+		scheme, token, _ := strings.Cut(header, " ")
+		if !strings.EqualFold(scheme, "Bearer") {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Use Authorization: Bearer <chave>"})
+			return
+		}
+
+		key, user, err := keys.Authenticate(strings.TrimSpace(token))
+		if errors.Is(err, services.ErrInvalidAPIKey) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			logger.Printf("Erro ao verificar chave de API: %v", err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Erro ao verificar chave de API"})
+			return
+		}
+
+		ok, remaining, retryAfter := keys.Allow(key)
+		c.Header("X-RateLimit-Limit", strconv.Itoa(key.RateLimit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		if !ok {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "Limite de requisições da chave de API excedido"})
+			return
+		}
+
+		if err := keys.Touch(key); err != nil {
+			logger.Printf("Erro ao registrar uso da chave %d: %v", key.ID, err)
+		}
+
+		c.Set(userContextKey, user)
+		c.Set(apiKeyContextKey, key)
+		c.Next()
+	}
+}
+
+// RequireSession recusa chaves de API, para que uma chave vazada não consiga
+// criar outras nem alterar a conta.
+func RequireSession(c *gin.Context) {
+	if CurrentAPIKey(c) != nil {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Esta rota exige login, não aceita chave de API"})
+		return
+	}
+	RequireAuth(c)
+}
+
+// CurrentAPIKey é nil quando a requisição veio com cookie de sessão ou anônima.
+func CurrentAPIKey(c *gin.Context) *models.APIKey {
+	if value, ok := c.Get(apiKeyContextKey); ok {
+		if key, ok := value.(*models.APIKey); ok {
+			return key
+		}
+	}
+	return nil
+}
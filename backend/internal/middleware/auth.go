@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"letterboxd-viewer-backend/internal/models"
+	"letterboxd-viewer-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	SessionCookieName = "cinedrome_session"
+
+	userContextKey = "auth.user"
+)
+
+// Authenticate identifica o usuário pelo cookie de sessão, sem bloquear
+// requisições anônimas; as rotas que exigem login usam RequireAuth.
+func Authenticate(auth *services.AuthService, logger *log.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, err := c.Cookie(SessionCookieName)
+		if err != nil || token == "" {
+			c.Next()
+			return
+		}
+
+		user, err := auth.Authenticate(token)
+		switch {
+		case err == nil:
+			c.Set(userContextKey, user)
+		case errors.Is(err, services.ErrInvalidSession):
+			// Cookie vencido ou de uma sessão encerrada: segue como anônimo.
+		default:
+			logger.Printf("Erro ao verificar sessão: %v", err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Erro ao verificar sessão"})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireAuth recusa a requisição quando Authenticate não encontrou um usuário.
+// Chaves de API só de leitura não passam pelas rotas de escrita.
+func RequireAuth(c *gin.Context) {
+	if CurrentUser(c) == nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Autenticação necessária"})
+		return
+	}
+	if key := CurrentAPIKey(c); key != nil && key.Scope != models.APIKeyScopeWrite && !isReadMethod(c.Request.Method) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Chave de API sem permissão de escrita"})
+		return
+	}
+	c.Next()
+}
+
+func isReadMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+func CurrentUser(c *gin.Context) *models.User {
+	if value, ok := c.Get(userContextKey); ok {
+		if user, ok := value.(*models.User); ok {
+			return user
+		}
+	}
+	return nil
+}
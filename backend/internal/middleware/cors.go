@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"letterboxd-viewer-backend/config"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+)
+
+// CORS libera as origens configuradas, incluindo curingas de subdomínio.
+// A checagem fica toda no OriginMatcher para que "*.exemplo.com" não seja
+// interpretado como um glob solto pelo gin-contrib/cors.
+func CORS(cfg config.CORSConfig) (gin.HandlerFunc, error) {
+	matcher, err := cfg.Matcher()
+	if err != nil {
+		return nil, err
+	}
+
+	return cors.New(cors.Config{
+		AllowOriginFunc:  matcher.Allowed,
+		AllowMethods:     cfg.AllowedMethods,
+		AllowHeaders:     cfg.AllowedHeaders,
+		AllowCredentials: cfg.AllowCredentials,
+		MaxAge:           cfg.MaxAge.Duration(),
+	}), nil
+}
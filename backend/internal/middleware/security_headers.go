@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"strconv"
+	"strings"
+
+	"letterboxd-viewer-backend/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SecurityHeaders adiciona os cabeçalhos de segurança a todas as respostas.
+// O HSTS só é enviado em conexões HTTPS, já que navegadores ignoram o
+// cabeçalho em HTTP puro e ele não deve vazar em ambientes de desenvolvimento.
+func SecurityHeaders(cfg config.SecurityConfig) gin.HandlerFunc {
+	hsts := ""
+	if maxAge := int64(cfg.HSTSMaxAge.Duration().Seconds()); maxAge > 0 {
+		hsts = "max-age=" + strconv.FormatInt(maxAge, 10) + "; includeSubDomains"
+	}
+
+	return func(c *gin.Context) {
+		header := c.Writer.Header()
+		header.Set("X-Content-Type-Options", "nosniff")
+		header.Set("X-Frame-Options", "DENY")
+		if cfg.ContentSecurityPolicy != "" {
+			header.Set("Content-Security-Policy", cfg.ContentSecurityPolicy)
+		}
+		if cfg.ReferrerPolicy != "" {
+			header.Set("Referrer-Policy", cfg.ReferrerPolicy)
+		}
+		if hsts != "" && IsTLS(c, cfg.TrustForwardedProto) {
+			header.Set("Strict-Transport-Security", hsts)
+		}
+
+		c.Next()
+	}
+}
+
+// IsTLS diz se a requisição chegou por HTTPS, direto ou via proxy confiável.
+func IsTLS(c *gin.Context, trustForwardedProto bool) bool {
+	if c.Request.TLS != nil {
+		return true
+	}
+	if !trustForwardedProto {
+		return false
+	}
+	proto, _, _ := strings.Cut(c.GetHeader("X-Forwarded-Proto"), ",")
+	return strings.EqualFold(strings.TrimSpace(proto), "https")
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"letterboxd-viewer-backend/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSecurityHeadersHSTS(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		trustForwarded bool
+		tls            bool
+		forwardedProto string
+		want           bool
+	}{
+		{name: "http puro", want: false},
+		{name: "tls direto", tls: true, want: true},
+		{name: "proxy não confiável", forwardedProto: "https", want: false},
+		{name: "proxy confiável", trustForwarded: true, forwardedProto: "https", want: true},
+		{name: "proxy confiável com lista", trustForwarded: true, forwardedProto: "https, http", want: true},
+		{name: "proxy confiável em http", trustForwarded: true, forwardedProto: "http", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(SecurityHeaders(config.SecurityConfig{
+				HSTSMaxAge:          config.Duration(365 * 24 * time.Hour),
+				TrustForwardedProto: tt.trustForwarded,
+			}))
+			router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.tls {
+				req.TLS = &tls.ConnectionState{}
+			}
+			if tt.forwardedProto != "" {
+				req.Header.Set("X-Forwarded-Proto", tt.forwardedProto)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			hsts := w.Header().Get("Strict-Transport-Security")
+			if got := hsts != ""; got != tt.want {
+				t.Errorf("HSTS enviado = %v (%q), esperado %v", got, hsts, tt.want)
+			}
+			if w.Header().Get("X-Content-Type-Options") != "nosniff" {
+				t.Error("X-Content-Type-Options ausente")
+			}
+		})
+	}
+}
+
+func TestSecurityHeadersHSTSDisabled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(SecurityHeaders(config.SecurityConfig{}))
+	router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.TLS = &tls.ConnectionState{}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if hsts := w.Header().Get("Strict-Transport-Security"); hsts != "" {
+		t.Errorf("HSTS com max-age zero não deveria ser enviado: %q", hsts)
+	}
+}
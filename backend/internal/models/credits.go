@@ -0,0 +1,45 @@
+package models
+
+import "strings"
+
+type MovieCredits struct {
+	ID   int          `json:"id"`
+	Cast []CastMember `json:"cast"`
+	Crew []CrewMember `json:"crew"`
+}
+
+type CastMember struct {
+	CastID      int     `json:"cast_id"`
+	Character   string  `json:"character"`
+	CreditID    string  `json:"credit_id"`
+	Gender      int     `json:"gender"`
+	ID          int     `json:"id"`
+	Name        string  `json:"name"`
+	Order       int     `json:"order"`
+	ProfilePath *string `json:"profile_path"`
+}
+
+type CrewMember struct {
+	CreditID    string  `json:"credit_id"`
+	Department  string  `json:"department"`
+	Gender      int     `json:"gender"`
+	ID          int     `json:"id"`
+	Job         string  `json:"job"`
+	Name        string  `json:"name"`
+	ProfilePath *string `json:"profile_path"`
+}
+
+type Keyword struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func (c *MovieCredits) Directors() string {
+	var directors []string
+	for _, member := range c.Crew {
+		if member.Job == "Director" {
+			directors = append(directors, member.Name)
+		}
+	}
+	return strings.Join(directors, ", ")
+}
@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+// Critic é um membro do Letterboxd acompanhado por um ou mais usuários; o
+// feed dele é importado uma vez só, mesmo com vários seguidores.
+type Critic struct {
+	Username     string     `json:"username"`
+	DisplayName  string     `json:"displayName"`
+	EntryCount   int        `json:"entryCount"`
+	LastSyncedAt *time.Time `json:"lastSyncedAt"`
+	FollowedAt   time.Time  `json:"followedAt"`
+}
+
+type CriticEntry struct {
+	ID           int    `json:"id"`
+	Critic       string `json:"critic"`
+	GUID         string `json:"guid"`
+	TMDBId       string `json:"tmdbId"`
+	Title        string `json:"title"`
+	Year         string `json:"year"`
+	WatchedDate  string `json:"watchedDate"`
+	MemberRating string `json:"memberRating"`
+	Review       string `json:"review"`
+	Rewatch      bool   `json:"rewatch"`
+	Link         string `json:"link"`
+}
+
+// Opinion resume a entrada mais recente de alguém sobre um filme: a minha,
+// vinda do diário, ou a de um crítico acompanhado.
+type Opinion struct {
+	Username      string `json:"username,omitempty"`
+	DisplayName   string `json:"displayName,omitempty"`
+	MemberRating  string `json:"memberRating"`
+	WatchedDate   string `json:"watchedDate"`
+	Rewatch       bool   `json:"rewatch"`
+	ReviewSnippet string `json:"reviewSnippet"`
+	Link          string `json:"link,omitempty"`
+}
+
+type MovieOpinions struct {
+	TMDBId  string    `json:"tmdbId"`
+	Mine    *Opinion  `json:"mine"`
+	Critics []Opinion `json:"critics"`
+}
+
+// CriticSimilarity compara as notas do usuário e de um crítico nos filmes
+// que os dois registraram. As correlações ficam nulas quando a sobreposição
+// é pequena demais ou quando um dos dois deu a mesma nota a tudo.
+type CriticSimilarity struct {
+	Critic      string   `json:"critic"`
+	DisplayName string   `json:"displayName"`
+	Overlap     int      `json:"overlap"`
+	Pearson     *float64 `json:"pearson"`
+	Spearman    *float64 `json:"spearman"`
+	MeanAbsDiff *float64 `json:"meanAbsDiff"`
+	// Fração dos filmes em comum com notas a até meia estrela de distância.
+	Agreement  *float64  `json:"agreement"`
+	Score      float64   `json:"score"`
+	ComputedAt time.Time `json:"computedAt"`
+}
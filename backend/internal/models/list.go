@@ -0,0 +1,32 @@
+package models
+
+type List struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	SourceURL   string `json:"sourceUrl"`
+	ItemCount   int    `json:"itemCount"`
+	CreatedAt   string `json:"createdAt"`
+}
+
+type ListItem struct {
+	TMDBId     string `json:"tmdbId"`
+	Title      string `json:"title"`
+	Year       string `json:"year"`
+	PosterPath string `json:"poster_path"`
+	Position   int    `json:"position"`
+	Notes      string `json:"notes"`
+	Watched    bool   `json:"watched"`
+	GUID       string `json:"guid,omitempty"`
+}
+
+type ListDetail struct {
+	List
+	Items []ListItem `json:"items"`
+}
+
+type Tag struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
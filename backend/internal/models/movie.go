@@ -2,35 +2,55 @@ package models
 
 import (
 	"fmt"
+	"html"
+	"regexp"
+	"strings"
 	"time"
 )
 
+var (
+	htmlImageParagraph = regexp.MustCompile(`(?is)<p>\s*<img[^>]*>\s*</p>`)
+	htmlTag            = regexp.MustCompile(`(?s)<[^>]*>`)
+	htmlParagraphEnd   = regexp.MustCompile(`(?i)</p>|<br\s*/?>`)
+)
+
+const (
+	SourceLetterboxd = "letterboxd"
+	SourceManual     = "manual"
+)
+
 type Movie struct {
-	ID                  int    `json:"id"`
-	Title               string `json:"title"`
-	Year                string `json:"year"`
-	Image               string `json:"image"`
-	WatchedDate         string `json:"watchedDate"`
-	MemberRating        string `json:"memberRating"`
-	Description         string `json:"description"`
-	IMDBRating          string `json:"imdbRating"`
-	Genre               string `json:"genre"`
-	Plot                string `json:"plot"`
-	Director            string `json:"director"`
-	TMDBId              string `json:"tmdbId"`
-	Runtime             int    `json:"runtime"`
-	ReleaseDate         string `json:"releaseDate"`
-	Budget              int    `json:"budget"`
-	Revenue             int    `json:"revenue"`
-	Tagline             string `json:"tagline"`
-	Status              string `json:"status"`
-	OriginalLanguage    string `json:"original_language"`
-	ProductionCompanies string `json:"production_companies"`
-	SpokenLanguages     string `json:"spoken_languages"`
-	PosterPath          string `json:"poster_path"`
-	BackdropPath        string `json:"backdrop_path"`
-	Homepage            string `json:"homepage"`
-	GUID                string `json:"guid"`
+	ID                  int       `json:"id"`
+	Title               string    `json:"title"`
+	Year                string    `json:"year"`
+	Image               string    `json:"image"`
+	WatchedDate         string    `json:"watchedDate"`
+	MemberRating        string    `json:"memberRating"`
+	Description         string    `json:"description"`
+	IMDBRating          string    `json:"imdbRating"`
+	Genre               string    `json:"genre"`
+	Plot                string    `json:"plot"`
+	Director            string    `json:"director"`
+	TMDBId              string    `json:"tmdbId"`
+	Runtime             int       `json:"runtime"`
+	ReleaseDate         string    `json:"releaseDate"`
+	Budget              int       `json:"budget"`
+	Revenue             int       `json:"revenue"`
+	Tagline             string    `json:"tagline"`
+	Status              string    `json:"status"`
+	OriginalLanguage    string    `json:"original_language"`
+	ProductionCompanies string    `json:"production_companies"`
+	SpokenLanguages     string    `json:"spoken_languages"`
+	PosterPath          string    `json:"poster_path"`
+	BackdropPath        string    `json:"backdrop_path"`
+	Homepage            string    `json:"homepage"`
+	GUID                string    `json:"guid"`
+	OriginalTitle       string    `json:"original_title"`
+	ProductionCountries string    `json:"production_countries"`
+	Rewatch             bool      `json:"rewatch"`
+	Source              string    `json:"source"`
+	UpdatedAt           time.Time `json:"updatedAt"`
+	UserID              int       `json:"-"`
 }
 
 func (m *Movie) ParsedWatchedDate() (*time.Time, error) {
@@ -92,3 +112,27 @@ func (m *Movie) FullBackdropURL() string {
 	}
 	return "https://image.tmdb.org/t/p/original" + m.BackdropPath
 }
+
+type SearchResult struct {
+	Movie   Movie   `json:"movie"`
+	Rank    float64 `json:"rank"`
+	Snippet string  `json:"snippet"`
+}
+
+// ReviewText extrai o texto da resenha da descrição em HTML do Letterboxd,
+// ignorando o pôster e a linha padrão "Watched on ..." de entradas sem resenha.
+func (m *Movie) ReviewText() string {
+	text := htmlImageParagraph.ReplaceAllString(m.Description, "")
+	text = htmlParagraphEnd.ReplaceAllString(text, "\n")
+	text = html.UnescapeString(htmlTag.ReplaceAllString(text, ""))
+
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "Watched on ") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n\n")
+}
@@ -0,0 +1,41 @@
+package models
+
+type Person struct {
+	ID                 int     `json:"id"`
+	Name               string  `json:"name"`
+	Biography          string  `json:"biography"`
+	Birthday           *string `json:"birthday"`
+	Deathday           *string `json:"deathday"`
+	PlaceOfBirth       *string `json:"place_of_birth"`
+	KnownForDepartment string  `json:"known_for_department"`
+	Gender             int     `json:"gender"`
+	ProfilePath        *string `json:"profile_path"`
+	Homepage           *string `json:"homepage"`
+	IMDBId             string  `json:"imdb_id"`
+}
+
+type PersonRole struct {
+	Department string `json:"department"`
+	Job        string `json:"job"`
+	Character  string `json:"character,omitempty"`
+}
+
+type PersonFilm struct {
+	Movie Movie        `json:"movie"`
+	Roles []PersonRole `json:"roles"`
+}
+
+type PersonPage struct {
+	Person        *Person      `json:"person"`
+	FilmCount     int          `json:"filmCount"`
+	AverageRating *float64     `json:"averageRating"`
+	Films         []PersonFilm `json:"films"`
+}
+
+type PersonRanking struct {
+	ID            int      `json:"id"`
+	Name          string   `json:"name"`
+	ProfilePath   *string  `json:"profile_path"`
+	FilmCount     int      `json:"filmCount"`
+	AverageRating *float64 `json:"averageRating"`
+}
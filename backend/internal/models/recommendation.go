@@ -0,0 +1,54 @@
+package models
+
+// CriticRating é a nota mais recente de um crítico parecido com o usuário
+// para um filme que o usuário ainda não registrou.
+type CriticRating struct {
+	Critic      string   `json:"critic"`
+	DisplayName string   `json:"displayName"`
+	Rating      float64  `json:"rating"`
+	Similarity  float64  `json:"similarity"`
+	Agreement   *float64 `json:"agreement"`
+}
+
+// Recommendation é um filme sugerido a partir das notas dos críticos
+// acompanhados, ponderadas pela similaridade de cada um com o usuário.
+type Recommendation struct {
+	TMDBId          string         `json:"tmdbId"`
+	Title           string         `json:"title"`
+	Year            string         `json:"year"`
+	Genre           string         `json:"genre"`
+	Runtime         int            `json:"runtime"`
+	PosterPath      string         `json:"poster_path"`
+	PredictedRating float64        `json:"predictedRating"`
+	Ratings         []CriticRating `json:"ratings"`
+	Explanations    []string       `json:"explanations"`
+}
+
+// SimilarMovie é um filme parecido com outro pelo conteúdo: gêneros,
+// direção, elenco, palavras-chave e década, além das sugestões do TMDb.
+type SimilarMovie struct {
+	TMDBId     string `json:"tmdbId"`
+	Title      string `json:"title"`
+	Year       string `json:"year"`
+	Genre      string `json:"genre"`
+	PosterPath string `json:"poster_path"`
+	Watched    bool   `json:"watched"`
+	// Entrada mais recente do filme no diário, quando ele já foi visto.
+	GUID    string   `json:"guid,omitempty"`
+	Score   float64  `json:"score"`
+	Reasons []string `json:"reasons"`
+}
+
+const (
+	SharedDirector = "director"
+	SharedCast     = "cast"
+	SharedKeyword  = "keyword"
+)
+
+// SharedFeature é uma pessoa ou palavra-chave que um filme candidato tem em
+// comum com o filme de referência.
+type SharedFeature struct {
+	TMDBId string
+	Kind   string
+	Name   string
+}
@@ -0,0 +1,86 @@
+package models
+
+type CountStat struct {
+	Label string `json:"label"`
+	Count int    `json:"count"`
+}
+
+type AverageStat struct {
+	Label   string  `json:"label"`
+	Average float64 `json:"average"`
+	Count   int     `json:"count"`
+}
+
+type RatingBucket struct {
+	Rating float64 `json:"rating"`
+	Count  int     `json:"count"`
+}
+
+type Stats struct {
+	TotalMovies          int            `json:"totalMovies"`
+	TotalHours           float64        `json:"totalHours"`
+	ByGenre              []CountStat    `json:"byGenre"`
+	ByDecade             []CountStat    `json:"byDecade"`
+	ByLanguage           []CountStat    `json:"byLanguage"`
+	ByCountry            []CountStat    `json:"byCountry"`
+	ByMonth              []CountStat    `json:"byMonth"`
+	RatingDistribution   []RatingBucket `json:"ratingDistribution"`
+	AverageRatingByGenre []AverageStat  `json:"averageRatingByGenre"`
+}
+
+type RatingPoint struct {
+	Period            string   `json:"period"`
+	Count             int      `json:"count"`
+	RatedCount        int      `json:"ratedCount"`
+	AverageRating     *float64 `json:"averageRating"`
+	RollingAverage    *float64 `json:"rollingAverage"`
+	AverageTMDBRating *float64 `json:"averageTmdbRating"`
+	AverageDelta      *float64 `json:"averageDelta"`
+}
+
+type YearReview struct {
+	Year          int         `json:"year"`
+	FilmsWatched  int         `json:"filmsWatched"`
+	HoursWatched  float64     `json:"hoursWatched"`
+	NewFilms      int         `json:"newFilms"`
+	Rewatches     int         `json:"rewatches"`
+	AverageRating *float64    `json:"averageRating"`
+	TopRated      []Movie     `json:"topRated"`
+	TopDirectors  []CountStat `json:"topDirectors"`
+	TopActors     []CountStat `json:"topActors"`
+	Genres        []CountStat `json:"genres"`
+	Longest       *Movie      `json:"longest"`
+	Shortest      *Movie      `json:"shortest"`
+	Oldest        *Movie      `json:"oldest"`
+	Newest        *Movie      `json:"newest"`
+	BusiestMonth  *CountStat  `json:"busiestMonth"`
+	BusiestDay    *CountStat  `json:"busiestDay"`
+	FirstFilm     *Movie      `json:"firstFilm"`
+	LastFilm      *Movie      `json:"lastFilm"`
+
+	// Filmes do ano sem créditos guardados ficam fora de TopActors até
+	// passarem pelo comando enrich.
+	FilmsWithoutCredits int `json:"filmsWithoutCredits"`
+}
+
+type CalendarDay struct {
+	Date          string   `json:"date"`
+	Count         int      `json:"count"`
+	AverageRating *float64 `json:"averageRating"`
+}
+
+type Streak struct {
+	Length int    `json:"length"`
+	Start  string `json:"start,omitempty"`
+	End    string `json:"end,omitempty"`
+}
+
+type Calendar struct {
+	From          string        `json:"from"`
+	To            string        `json:"to"`
+	Days          []CalendarDay `json:"days"`
+	ActiveDays    int           `json:"activeDays"`
+	CurrentStreak Streak        `json:"currentStreak"`
+	LongestStreak Streak        `json:"longestStreak"`
+	LongestGap    Streak        `json:"longestGap"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+type User struct {
+	ID                 int       `json:"id"`
+	Username           string    `json:"username"`
+	LetterboxdUsername string    `json:"letterboxdUsername"`
+	CreatedAt          time.Time `json:"createdAt"`
+	// Arquivo RSS local, configurável só pela linha de comando.
+	RSSFilePath string `json:"-"`
+}
+
+const (
+	APIKeyScopeRead  = "read"
+	APIKeyScopeWrite = "write"
+)
+
+// APIKey nunca carrega a chave em si: só o prefixo, para o usuário
+// reconhecê-la na listagem.
+type APIKey struct {
+	ID         int        `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scope      string     `json:"scope"`
+	RateLimit  int        `json:"rateLimit"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	UserID     int        `json:"-"`
+}
@@ -0,0 +1,22 @@
+package models
+
+const (
+	WatchlistSourceManual = "manual"
+	WatchlistSourceRSS    = "rss"
+	WatchlistSourceCSV    = "csv"
+)
+
+type WatchlistItem struct {
+	ID            int    `json:"id"`
+	TMDBId        string `json:"tmdbId"`
+	Title         string `json:"title"`
+	OriginalTitle string `json:"original_title"`
+	Year          string `json:"year"`
+	Genre         string `json:"genre"`
+	Plot          string `json:"plot"`
+	Runtime       int    `json:"runtime"`
+	PosterPath    string `json:"poster_path"`
+	LetterboxdURI string `json:"letterboxd_uri"`
+	Source        string `json:"source"`
+	AddedDate     string `json:"addedDate"`
+}
@@ -0,0 +1,138 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"letterboxd-viewer-backend/internal/models"
+	"time"
+)
+
+const apiKeyColumns = `id, name, prefix, scope, rate_limit, created_at, last_used_at, user_id`
+
+type APIKeyRepository struct {
+	DB *sql.DB
+}
+
+func NewAPIKeyRepository(db *sql.DB) *APIKeyRepository {
+	return &APIKeyRepository{
+		DB: db,
+	}
+}
+
+func (r *APIKeyRepository) CreateAPIKey(key *models.APIKey, keyHash string) error {
+	query := `
+		INSERT INTO public.api_keys (user_id, name, prefix, key_hash, scope, rate_limit)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := r.DB.QueryRowContext(ctx, query, key.UserID, key.Name, key.Prefix, keyHash, key.Scope, key.RateLimit).
+		Scan(&key.ID, &key.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("erro ao criar chave de API: %w", err)
+	}
+
+	return nil
+}
+
+func (r *APIKeyRepository) GetAPIKeys(userID int) ([]models.APIKey, error) {
+	keys := []models.APIKey{}
+	query := `SELECT ` + apiKeyColumns + ` FROM public.api_keys WHERE user_id=$1 ORDER BY id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := r.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar chaves de API: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler chave de API: %w", err)
+		}
+		keys = append(keys, *key)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre as chaves de API: %w", err)
+	}
+
+	return keys, nil
+}
+
+// GetAPIKeyUser devolve a chave e o dono dela; retorna sql.ErrNoRows quando
+// a chave não existe ou foi revogada.
+func (r *APIKeyRepository) GetAPIKeyUser(keyHash string) (*models.APIKey, *models.User, error) {
+	query := `
+		SELECT k.id, k.name, k.prefix, k.scope, k.rate_limit, k.created_at, k.last_used_at, k.user_id,
+			u.id, u.username, u.letterboxd_username, u.rss_file_path, u.created_at
+		FROM public.api_keys k
+		JOIN public.users u ON u.id = k.user_id
+		WHERE k.key_hash=$1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var user models.User
+	key, err := scanAPIKey(r.DB.QueryRowContext(ctx, query, keyHash),
+		&user.ID, &user.Username, &user.LetterboxdUsername, &user.RSSFilePath, &user.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil, err
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao buscar chave de API: %w", err)
+	}
+
+	return key, &user, nil
+}
+
+// TouchAPIKey registra o uso da chave com resolução de um minuto, para não
+// gerar uma escrita a cada requisição.
+func (r *APIKeyRepository) TouchAPIKey(id int) error {
+	query := `
+		UPDATE public.api_keys SET last_used_at = now()
+		WHERE id=$1 AND (last_used_at IS NULL OR last_used_at < now() - interval '1 minute')`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := r.DB.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("erro ao registrar uso da chave de API: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteAPIKey revoga a chave; a remoção é imediata para as próximas requisições.
+func (r *APIKeyRepository) DeleteAPIKey(userID, id int) error {
+	query := `DELETE FROM public.api_keys WHERE id=$1 AND user_id=$2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := r.DB.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return fmt.Errorf("erro ao revogar chave de API: %w", err)
+	}
+
+	return expectAffected(result)
+}
+
+func scanAPIKey(row rowScanner, extra ...any) (*models.APIKey, error) {
+	var key models.APIKey
+	var lastUsedAt sql.NullTime
+	dest := []any{&key.ID, &key.Name, &key.Prefix, &key.Scope, &key.RateLimit, &key.CreatedAt, &lastUsedAt, &key.UserID}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
+	return &key, nil
+}
@@ -0,0 +1,63 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type CacheRepository struct {
+	DB *sql.DB
+}
+
+func NewCacheRepository(db *sql.DB) *CacheRepository {
+	return &CacheRepository{
+		DB: db,
+	}
+}
+
+func (r *CacheRepository) Get(key string, maxAge time.Duration) ([]byte, bool, error) {
+	var payload []byte
+	query := `SELECT payload FROM public.tmdb_cache WHERE key=$1 AND fetched_at > now() - $2::interval`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := r.DB.QueryRowContext(ctx, query, key, fmt.Sprintf("%d seconds", int(maxAge.Seconds()))).Scan(&payload)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("erro ao ler cache: %w", err)
+	}
+
+	return payload, true, nil
+}
+
+func (r *CacheRepository) Set(key string, payload []byte) error {
+	query := `
+		INSERT INTO public.tmdb_cache (key, payload, fetched_at)
+		VALUES ($1, $2, now())
+		ON CONFLICT (key) DO UPDATE SET payload = EXCLUDED.payload, fetched_at = EXCLUDED.fetched_at`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := r.DB.ExecContext(ctx, query, key, string(payload)); err != nil {
+		return fmt.Errorf("erro ao gravar cache: %w", err)
+	}
+
+	return nil
+}
+
+func GetCached(db *sql.DB, key string, maxAge time.Duration) ([]byte, bool, error) {
+	repo := NewCacheRepository(db)
+	return repo.Get(key, maxAge)
+}
+
+func SetCached(db *sql.DB, key string, payload []byte) error {
+	repo := NewCacheRepository(db)
+	return repo.Set(key, payload)
+}
@@ -0,0 +1,183 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"letterboxd-viewer-backend/internal/models"
+	"strconv"
+	"time"
+)
+
+type CreditsRepository struct {
+	DB *sql.DB
+}
+
+func NewCreditsRepository(db *sql.DB) *CreditsRepository {
+	return &CreditsRepository{
+		DB: db,
+	}
+}
+
+func (r *CreditsRepository) SaveCredits(tmdbId string, credits *models.MovieCredits) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar transação de créditos: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, query := range []string{
+		`DELETE FROM public.movie_cast WHERE tmdb_id=$1`,
+		`DELETE FROM public.movie_crew WHERE tmdb_id=$1`,
+	} {
+		if _, err := tx.ExecContext(ctx, query, tmdbId); err != nil {
+			return fmt.Errorf("erro ao limpar créditos anteriores: %w", err)
+		}
+	}
+
+	upsertPerson := `
+		INSERT INTO public.people (id, name, gender, profile_path)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (id) DO UPDATE
+		SET name = EXCLUDED.name, gender = EXCLUDED.gender, profile_path = EXCLUDED.profile_path`
+
+	for _, member := range credits.Cast {
+		if _, err := tx.ExecContext(ctx, upsertPerson, member.ID, member.Name, member.Gender, member.ProfilePath); err != nil {
+			return fmt.Errorf("erro ao salvar pessoa %d: %w", member.ID, err)
+		}
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO public.movie_cast (tmdb_id, credit_id, person_id, cast_id, character, ord)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (tmdb_id, credit_id) DO NOTHING`,
+			tmdbId, member.CreditID, member.ID, member.CastID, member.Character, member.Order,
+		)
+		if err != nil {
+			return fmt.Errorf("erro ao salvar elenco: %w", err)
+		}
+	}
+
+	for _, member := range credits.Crew {
+		if _, err := tx.ExecContext(ctx, upsertPerson, member.ID, member.Name, member.Gender, member.ProfilePath); err != nil {
+			return fmt.Errorf("erro ao salvar pessoa %d: %w", member.ID, err)
+		}
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO public.movie_crew (tmdb_id, credit_id, person_id, department, job)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (tmdb_id, credit_id) DO NOTHING`,
+			tmdbId, member.CreditID, member.ID, member.Department, member.Job,
+		)
+		if err != nil {
+			return fmt.Errorf("erro ao salvar equipe técnica: %w", err)
+		}
+	}
+
+	// Grava a linha mesmo sem elenco, para não buscar o filme no TMDb de novo.
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO public.movie_credits_index (tmdb_id, cast_vector, fetched_at)
+		SELECT $1, coalesce(setweight(to_tsvector('simple', string_agg(p.name, ' ')), 'B'), ''::tsvector), now()
+		FROM public.movie_cast c
+		JOIN public.people p ON p.id = c.person_id
+		WHERE c.tmdb_id = $1
+		ON CONFLICT (tmdb_id) DO UPDATE
+		SET cast_vector = EXCLUDED.cast_vector, fetched_at = EXCLUDED.fetched_at`, tmdbId)
+	if err != nil {
+		return fmt.Errorf("erro ao indexar elenco: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("erro ao confirmar créditos: %w", err)
+	}
+
+	return nil
+}
+
+func (r *CreditsRepository) GetCredits(tmdbId string) (*models.MovieCredits, error) {
+	credits := &models.MovieCredits{
+		Cast: []models.CastMember{},
+		Crew: []models.CrewMember{},
+	}
+	credits.ID, _ = strconv.Atoi(tmdbId)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	castRows, err := r.DB.QueryContext(ctx, `
+		SELECT c.cast_id, c.character, c.credit_id, p.gender, p.id, p.name, c.ord, p.profile_path
+		FROM public.movie_cast c
+		JOIN public.people p ON p.id = c.person_id
+		WHERE c.tmdb_id=$1
+		ORDER BY c.ord`, tmdbId)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar elenco: %w", err)
+	}
+	defer castRows.Close()
+
+	for castRows.Next() {
+		var member models.CastMember
+		err := castRows.Scan(
+			&member.CastID, &member.Character, &member.CreditID, &member.Gender,
+			&member.ID, &member.Name, &member.Order, &member.ProfilePath,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler elenco: %w", err)
+		}
+		credits.Cast = append(credits.Cast, member)
+	}
+	if err = castRows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre o elenco: %w", err)
+	}
+
+	crewRows, err := r.DB.QueryContext(ctx, `
+		SELECT c.credit_id, c.department, p.gender, p.id, c.job, p.name, p.profile_path
+		FROM public.movie_crew c
+		JOIN public.people p ON p.id = c.person_id
+		WHERE c.tmdb_id=$1
+		ORDER BY c.department, c.job, p.name`, tmdbId)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar equipe técnica: %w", err)
+	}
+	defer crewRows.Close()
+
+	for crewRows.Next() {
+		var member models.CrewMember
+		err := crewRows.Scan(
+			&member.CreditID, &member.Department, &member.Gender, &member.ID,
+			&member.Job, &member.Name, &member.ProfilePath,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler equipe técnica: %w", err)
+		}
+		credits.Crew = append(credits.Crew, member)
+	}
+	if err = crewRows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre a equipe técnica: %w", err)
+	}
+
+	// Sem créditos, só devolvemos a lista vazia se o filme já foi buscado.
+	if len(credits.Cast) == 0 && len(credits.Crew) == 0 {
+		var fetched bool
+		err := r.DB.QueryRowContext(ctx,
+			`SELECT EXISTS (SELECT 1 FROM public.movie_credits_index WHERE tmdb_id=$1)`, tmdbId).Scan(&fetched)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao verificar créditos: %w", err)
+		}
+		if !fetched {
+			return nil, sql.ErrNoRows
+		}
+	}
+
+	return credits, nil
+}
+
+func SaveCredits(db *sql.DB, tmdbId string, credits *models.MovieCredits) error {
+	repo := NewCreditsRepository(db)
+	return repo.SaveCredits(tmdbId, credits)
+}
+
+func GetCredits(db *sql.DB, tmdbId string) (*models.MovieCredits, error) {
+	repo := NewCreditsRepository(db)
+	return repo.GetCredits(tmdbId)
+}
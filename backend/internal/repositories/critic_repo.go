@@ -0,0 +1,307 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"letterboxd-viewer-backend/internal/models"
+	"time"
+)
+
+var ErrAlreadyFollowing = errors.New("crítico já acompanhado")
+
+type CriticRepository struct {
+	DB *sql.DB
+}
+
+func NewCriticRepository(db *sql.DB) *CriticRepository {
+	return &CriticRepository{
+		DB: db,
+	}
+}
+
+func (r *CriticRepository) FollowCritic(userID int, username string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `INSERT INTO public.critics (username) VALUES ($1) ON CONFLICT DO NOTHING`, username); err != nil {
+		return fmt.Errorf("erro ao cadastrar crítico: %w", err)
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO public.critic_follows (user_id, critic) VALUES ($1, $2)
+		ON CONFLICT DO NOTHING`, userID, username)
+	if err != nil {
+		return fmt.Errorf("erro ao acompanhar crítico: %w", err)
+	}
+	if err := expectAffected(result); err == sql.ErrNoRows {
+		return ErrAlreadyFollowing
+	} else if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UnfollowCritic remove também o crítico e as entradas dele quando ninguém
+// mais o acompanha.
+func (r *CriticRepository) UnfollowCritic(userID int, username string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM public.critic_follows WHERE user_id=$1 AND critic=$2`, userID, username)
+	if err != nil {
+		return fmt.Errorf("erro ao deixar de acompanhar crítico: %w", err)
+	}
+	if err := expectAffected(result); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM public.critics c
+		WHERE c.username=$1 AND NOT EXISTS (SELECT 1 FROM public.critic_follows f WHERE f.critic = c.username)`, username)
+	if err != nil {
+		return fmt.Errorf("erro ao remover crítico: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func (r *CriticRepository) GetFollowedCritics(userID int) ([]models.Critic, error) {
+	critics := []models.Critic{}
+	query := `
+		SELECT c.username, c.display_name, count(e.id), c.last_synced_at, f.created_at
+		FROM public.critic_follows f
+		JOIN public.critics c ON c.username = f.critic
+		LEFT JOIN public.critic_entries e ON e.critic = c.username
+		WHERE f.user_id=$1
+		GROUP BY c.username, f.created_at
+		ORDER BY c.username`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar críticos: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var critic models.Critic
+		var lastSyncedAt sql.NullTime
+		if err := rows.Scan(&critic.Username, &critic.DisplayName, &critic.EntryCount, &lastSyncedAt, &critic.FollowedAt); err != nil {
+			return nil, fmt.Errorf("erro ao ler crítico: %w", err)
+		}
+		if lastSyncedAt.Valid {
+			critic.LastSyncedAt = &lastSyncedAt.Time
+		}
+		critics = append(critics, critic)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre os críticos: %w", err)
+	}
+
+	return critics, nil
+}
+
+// GetCriticUsernames lista os críticos acompanhados pelo usuário ou, com
+// userID zero, por qualquer usuário.
+func (r *CriticRepository) GetCriticUsernames(userID int) ([]string, error) {
+	var usernames []string
+	query := `
+		SELECT DISTINCT critic FROM public.critic_follows
+		WHERE $1 = 0 OR user_id = $1
+		ORDER BY critic`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := r.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar críticos: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var username string
+		if err := rows.Scan(&username); err != nil {
+			return nil, fmt.Errorf("erro ao ler crítico: %w", err)
+		}
+		usernames = append(usernames, username)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre os críticos: %w", err)
+	}
+
+	return usernames, nil
+}
+
+// SaveCriticEntry insere a entrada ou atualiza nota e resenha, que podem ser
+// editadas no Letterboxd depois de publicadas. inserted indica uma entrada
+// nova e changed, qualquer alteração no banco.
+func (r *CriticRepository) SaveCriticEntry(entry *models.CriticEntry) (inserted, changed bool, err error) {
+	query := `
+		INSERT INTO public.critic_entries (
+			critic, guid, tmdb_id, title, year, watched_date, member_rating, review, rewatch, link
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (guid) DO UPDATE SET
+			member_rating = EXCLUDED.member_rating,
+			review = EXCLUDED.review,
+			rewatch = EXCLUDED.rewatch
+		WHERE (critic_entries.member_rating, critic_entries.review, critic_entries.rewatch)
+			IS DISTINCT FROM (EXCLUDED.member_rating, EXCLUDED.review, EXCLUDED.rewatch)
+		RETURNING id, (xmax = 0)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err = r.DB.QueryRowContext(
+		ctx,
+		query,
+		entry.Critic, entry.GUID, entry.TMDBId, entry.Title, entry.Year, toNullString(entry.WatchedDate),
+		entry.MemberRating, entry.Review, entry.Rewatch, entry.Link,
+	).Scan(&entry.ID, &inserted)
+	if err == sql.ErrNoRows {
+		// A entrada já existia sem nenhuma diferença.
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, fmt.Errorf("erro ao salvar entrada do crítico: %w", err)
+	}
+
+	return inserted, true, nil
+}
+
+// MovieMetadataKnown diz se gênero, duração e pôster do filme já estão
+// guardados, em algum diário ou nas entradas dos críticos.
+func (r *CriticRepository) MovieMetadataKnown(tmdbId string) (bool, error) {
+	var known bool
+	query := `
+		SELECT EXISTS (SELECT 1 FROM public.filmes WHERE tmdb_id=$1 AND genre <> '')
+			OR EXISTS (SELECT 1 FROM public.critic_entries WHERE tmdb_id=$1 AND metadata_fetched_at IS NOT NULL)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := r.DB.QueryRowContext(ctx, query, tmdbId).Scan(&known); err != nil {
+		return false, fmt.Errorf("erro ao verificar metadados do filme: %w", err)
+	}
+
+	return known, nil
+}
+
+// SaveMovieMetadata grava os dados do TMDb em todas as entradas do filme.
+func (r *CriticRepository) SaveMovieMetadata(tmdbId string, movie *models.Movie) error {
+	query := `
+		UPDATE public.critic_entries
+		SET genre=$2, runtime=$3, poster_path=$4, metadata_fetched_at=now()
+		WHERE tmdb_id=$1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := r.DB.ExecContext(ctx, query, tmdbId, movie.Genre, movie.Runtime, movie.PosterPath); err != nil {
+		return fmt.Errorf("erro ao salvar metadados do filme: %w", err)
+	}
+
+	return nil
+}
+
+func (r *CriticRepository) GetCriticFollowers(username string) ([]int, error) {
+	var userIDs []int
+	query := `SELECT user_id FROM public.critic_follows WHERE critic=$1 ORDER BY user_id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := r.DB.QueryContext(ctx, query, username)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar seguidores de %s: %w", username, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID int
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("erro ao ler seguidor: %w", err)
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre os seguidores: %w", err)
+	}
+
+	return userIDs, nil
+}
+
+func (r *CriticRepository) MarkCriticSynced(username, displayName string) error {
+	query := `UPDATE public.critics SET display_name=$2, last_synced_at=now() WHERE username=$1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := r.DB.ExecContext(ctx, query, username, displayName); err != nil {
+		return fmt.Errorf("erro ao atualizar crítico: %w", err)
+	}
+
+	return nil
+}
+
+// GetCriticOpinions traz, para cada crítico acompanhado pelo usuário, a
+// entrada mais recente sobre o filme. ReviewSnippet vem com a resenha inteira.
+func (r *CriticRepository) GetCriticOpinions(userID int, tmdbId string) ([]models.Opinion, error) {
+	opinions := []models.Opinion{}
+	query := `
+		SELECT DISTINCT ON (e.critic)
+			e.critic, c.display_name, e.member_rating, COALESCE(to_char(e.watched_date, 'YYYY-MM-DD'), ''),
+			e.rewatch, e.review, e.link
+		FROM public.critic_follows f
+		JOIN public.critics c ON c.username = f.critic
+		JOIN public.critic_entries e ON e.critic = f.critic
+		WHERE f.user_id=$1 AND e.tmdb_id=$2
+		ORDER BY e.critic, e.watched_date DESC NULLS LAST, e.id DESC`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := r.DB.QueryContext(ctx, query, userID, tmdbId)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar opiniões dos críticos: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var opinion models.Opinion
+		err := rows.Scan(
+			&opinion.Username, &opinion.DisplayName, &opinion.MemberRating, &opinion.WatchedDate,
+			&opinion.Rewatch, &opinion.ReviewSnippet, &opinion.Link,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler opinião do crítico: %w", err)
+		}
+		opinions = append(opinions, opinion)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre as opiniões dos críticos: %w", err)
+	}
+
+	return opinions, nil
+}
@@ -0,0 +1,88 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"letterboxd-viewer-backend/internal/models"
+	"time"
+)
+
+type KeywordRepository struct {
+	DB *sql.DB
+}
+
+func NewKeywordRepository(db *sql.DB) *KeywordRepository {
+	return &KeywordRepository{
+		DB: db,
+	}
+}
+
+// SaveKeywords substitui as palavras-chave guardadas para o filme e o marca
+// como buscado, mesmo que a lista esteja vazia.
+func (r *KeywordRepository) SaveKeywords(tmdbId string, keywords []models.Keyword) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar transação de palavras-chave: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM public.movie_keywords WHERE tmdb_id=$1`, tmdbId); err != nil {
+		return fmt.Errorf("erro ao limpar palavras-chave anteriores: %w", err)
+	}
+
+	for _, keyword := range keywords {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO public.movie_keywords (tmdb_id, keyword_id, name)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (tmdb_id, keyword_id) DO NOTHING`,
+			tmdbId, keyword.ID, keyword.Name,
+		)
+		if err != nil {
+			return fmt.Errorf("erro ao salvar palavra-chave %d: %w", keyword.ID, err)
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO public.movie_keywords_fetched (tmdb_id, fetched_at)
+		VALUES ($1, now())
+		ON CONFLICT (tmdb_id) DO UPDATE SET fetched_at = EXCLUDED.fetched_at`, tmdbId)
+	if err != nil {
+		return fmt.Errorf("erro ao marcar palavras-chave buscadas: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("erro ao confirmar palavras-chave: %w", err)
+	}
+
+	return nil
+}
+
+// KeywordsFetched diz se as palavras-chave do filme já foram buscadas no
+// TMDb, ainda que nenhuma tenha vindo.
+func (r *KeywordRepository) KeywordsFetched(tmdbId string) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM public.movie_keywords_fetched WHERE tmdb_id=$1)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := r.DB.QueryRowContext(ctx, query, tmdbId).Scan(&exists); err != nil {
+		return false, fmt.Errorf("erro ao verificar palavras-chave: %w", err)
+	}
+
+	return exists, nil
+}
+
+func SaveKeywords(db *sql.DB, tmdbId string, keywords []models.Keyword) error {
+	repo := NewKeywordRepository(db)
+	return repo.SaveKeywords(tmdbId, keywords)
+}
+
+func KeywordsFetched(db *sql.DB, tmdbId string) (bool, error) {
+	repo := NewKeywordRepository(db)
+	return repo.KeywordsFetched(tmdbId)
+}
@@ -0,0 +1,286 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"letterboxd-viewer-backend/internal/models"
+	"time"
+)
+
+type ListRepository struct {
+	DB *sql.DB
+}
+
+func NewListRepository(db *sql.DB) *ListRepository {
+	return &ListRepository{
+		DB: db,
+	}
+}
+
+func (r *ListRepository) GetLists(userID int) ([]models.List, error) {
+	lists := []models.List{}
+	query := `
+		SELECT l.id, l.name, l.description, l.source_url, count(li.tmdb_id), to_char(l.created_at, 'YYYY-MM-DD"T"HH24:MI:SSOF')
+		FROM public.lists l
+		LEFT JOIN public.list_items li ON li.list_id = l.id
+		WHERE l.user_id=$1
+		GROUP BY l.id
+		ORDER BY l.name`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := r.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar listas: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var list models.List
+		if err := rows.Scan(&list.ID, &list.Name, &list.Description, &list.SourceURL, &list.ItemCount, &list.CreatedAt); err != nil {
+			return nil, fmt.Errorf("erro ao ler lista: %w", err)
+		}
+		lists = append(lists, list)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre as listas: %w", err)
+	}
+
+	return lists, nil
+}
+
+// GetList só encontra listas do usuário informado; os itens são marcados
+// como assistidos de acordo com o diário dele.
+func (r *ListRepository) GetList(userID, id int) (*models.ListDetail, error) {
+	detail := &models.ListDetail{Items: []models.ListItem{}}
+	query := `
+		SELECT id, name, description, source_url, to_char(created_at, 'YYYY-MM-DD"T"HH24:MI:SSOF')
+		FROM public.lists
+		WHERE id=$1 AND user_id=$2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := r.DB.QueryRowContext(ctx, query, id, userID).Scan(
+		&detail.ID, &detail.Name, &detail.Description, &detail.SourceURL, &detail.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("erro ao buscar lista: %w", err)
+	}
+
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT li.tmdb_id, li.title, li.year, li.poster_path, li.position, li.notes, coalesce(f.guid, '')
+		FROM public.list_items li
+		LEFT JOIN LATERAL (
+			SELECT guid FROM public.filmes
+			WHERE user_id = $2 AND tmdb_id = li.tmdb_id
+			ORDER BY watched_date DESC NULLS LAST
+			LIMIT 1
+		) f ON TRUE
+		WHERE li.list_id=$1
+		ORDER BY li.position`, id, userID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar itens da lista: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item models.ListItem
+		err := rows.Scan(&item.TMDBId, &item.Title, &item.Year, &item.PosterPath, &item.Position, &item.Notes, &item.GUID)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler item da lista: %w", err)
+		}
+		item.Watched = item.GUID != ""
+		detail.Items = append(detail.Items, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre os itens da lista: %w", err)
+	}
+
+	detail.ItemCount = len(detail.Items)
+	return detail, nil
+}
+
+func (r *ListRepository) CreateList(userID int, list *models.List) error {
+	query := `
+		INSERT INTO public.lists (name, description, source_url, user_id)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, to_char(created_at, 'YYYY-MM-DD"T"HH24:MI:SSOF')`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := r.DB.QueryRowContext(ctx, query, list.Name, list.Description, list.SourceURL, userID).Scan(&list.ID, &list.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("erro ao criar lista: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ListRepository) UpdateList(userID int, list *models.List) error {
+	query := `UPDATE public.lists SET name=$3, description=$4 WHERE id=$1 AND user_id=$2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := r.DB.ExecContext(ctx, query, list.ID, userID, list.Name, list.Description)
+	if err != nil {
+		return fmt.Errorf("erro ao atualizar lista: %w", err)
+	}
+
+	return expectAffected(result)
+}
+
+func (r *ListRepository) DeleteList(userID, id int) error {
+	query := `DELETE FROM public.lists WHERE id=$1 AND user_id=$2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := r.DB.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return fmt.Errorf("erro ao remover lista: %w", err)
+	}
+
+	return expectAffected(result)
+}
+
+func (r *ListRepository) AddItem(listId int, item *models.ListItem) error {
+	query := `
+		INSERT INTO public.list_items (list_id, tmdb_id, title, year, poster_path, notes, position)
+		SELECT $1, $2, $3, $4, $5, $6, coalesce(max(position), 0) + 1
+		FROM public.list_items
+		WHERE list_id=$1
+		RETURNING position`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := r.DB.QueryRowContext(ctx, query,
+		listId, item.TMDBId, item.Title, item.Year, item.PosterPath, item.Notes,
+	).Scan(&item.Position)
+	if err != nil {
+		return fmt.Errorf("erro ao adicionar item à lista: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ListRepository) UpdateItemNotes(listId int, tmdbId, notes string) error {
+	query := `UPDATE public.list_items SET notes=$3 WHERE list_id=$1 AND tmdb_id=$2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := r.DB.ExecContext(ctx, query, listId, tmdbId, notes)
+	if err != nil {
+		return fmt.Errorf("erro ao atualizar notas do item: %w", err)
+	}
+
+	return expectAffected(result)
+}
+
+func (r *ListRepository) MoveItem(listId int, tmdbId string, position int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	var current, count int
+	err = tx.QueryRowContext(ctx, `
+		SELECT position, (SELECT count(*) FROM public.list_items WHERE list_id=$1)
+		FROM public.list_items
+		WHERE list_id=$1 AND tmdb_id=$2
+		FOR UPDATE`, listId, tmdbId).Scan(&current, &count)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return sql.ErrNoRows
+		}
+		return fmt.Errorf("erro ao buscar item da lista: %w", err)
+	}
+
+	position = max(1, min(position, count))
+	if position != current {
+		shift := `UPDATE public.list_items SET position = position + 1 WHERE list_id=$1 AND position >= $2 AND position < $3`
+		args := []any{listId, position, current}
+		if position > current {
+			shift = `UPDATE public.list_items SET position = position - 1 WHERE list_id=$1 AND position > $2 AND position <= $3`
+			args = []any{listId, current, position}
+		}
+		if _, err := tx.ExecContext(ctx, shift, args...); err != nil {
+			return fmt.Errorf("erro ao reordenar itens da lista: %w", err)
+		}
+
+		_, err := tx.ExecContext(ctx, `UPDATE public.list_items SET position=$3 WHERE list_id=$1 AND tmdb_id=$2`,
+			listId, tmdbId, position)
+		if err != nil {
+			return fmt.Errorf("erro ao mover item da lista: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("erro ao confirmar reordenação: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ListRepository) RemoveItem(listId int, tmdbId string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	var position int
+	err = tx.QueryRowContext(ctx, `DELETE FROM public.list_items WHERE list_id=$1 AND tmdb_id=$2 RETURNING position`,
+		listId, tmdbId).Scan(&position)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return sql.ErrNoRows
+		}
+		return fmt.Errorf("erro ao remover item da lista: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE public.list_items SET position = position - 1 WHERE list_id=$1 AND position > $2`,
+		listId, position)
+	if err != nil {
+		return fmt.Errorf("erro ao reordenar itens da lista: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("erro ao confirmar remoção do item: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ListRepository) ItemExists(listId int, tmdbId string) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM public.list_items WHERE list_id=$1 AND tmdb_id=$2)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := r.DB.QueryRowContext(ctx, query, listId, tmdbId).Scan(&exists); err != nil {
+		return false, fmt.Errorf("erro ao verificar item da lista: %w", err)
+	}
+
+	return exists, nil
+}
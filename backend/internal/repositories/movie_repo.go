@@ -9,6 +9,22 @@ import (
 	"time"
 )
 
+const movieColumns = `
+	id, title, year, COALESCE(to_char(watched_date, 'YYYY-MM-DD'), ''), member_rating, description,
+	imdb_rating, genre, plot, director, tmdb_id, runtime, COALESCE(to_char(release_date, 'YYYY-MM-DD'), ''),
+	budget, revenue, tagline, status, original_language, production_companies, spoken_languages,
+	poster_path, backdrop_path, homepage, guid, original_title, production_countries, rewatch, source, updated_at,
+	coalesce(user_id, 0)`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+type MovieFilter struct {
+	Tag    string
+	ListID int
+}
+
 type MovieRepository struct {
 	DB *sql.DB
 }
@@ -19,14 +35,16 @@ func NewMovieRepository(db *sql.DB) *MovieRepository {
 	}
 }
 
-func (r *MovieRepository) CheckMovieExists(guid string) (bool, error) {
+// CheckMovieExists procura o GUID só no diário do usuário: o mesmo item do
+// RSS pode estar no diário de mais de uma conta.
+func (r *MovieRepository) CheckMovieExists(userID int, guid string) (bool, error) {
 	var exists bool
-	query := `SELECT EXISTS(SELECT 1 FROM public.filmes WHERE guid=$1)`
+	query := `SELECT EXISTS(SELECT 1 FROM public.filmes WHERE user_id=$1 AND guid=$2)`
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	err := r.DB.QueryRowContext(ctx, query, guid).Scan(&exists)
+	err := r.DB.QueryRowContext(ctx, query, userID, guid).Scan(&exists)
 	if err != nil {
 		return false, fmt.Errorf("erro ao verificar existência do filme: %w", err)
 	}
@@ -39,43 +57,56 @@ func (r *MovieRepository) InsertMovie(movie *models.Movie) error {
 		INSERT INTO filmes (
 			title, year, watched_date, member_rating, description, imdb_rating, genre, plot, director,
 			tmdb_id, runtime, release_date, budget, revenue, tagline, status, original_language,
-			production_companies, spoken_languages, poster_path, backdrop_path, homepage, guid
+			production_companies, spoken_languages, poster_path, backdrop_path, homepage, guid, original_title,
+			production_countries, rewatch, source, user_id
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28
 		)`
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	_, err := r.DB.ExecContext(
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(
 		ctx,
 		query,
 		movie.Title, movie.Year, toNullString(movie.WatchedDate), movie.MemberRating, movie.Description, movie.IMDBRating,
 		movie.Genre, movie.Plot, movie.Director, movie.TMDBId, movie.Runtime, toNullString(movie.ReleaseDate), movie.Budget,
 		movie.Revenue, movie.Tagline, movie.Status, movie.OriginalLanguage, movie.ProductionCompanies,
-		movie.SpokenLanguages, movie.PosterPath, movie.BackdropPath, movie.Homepage, movie.GUID,
+		movie.SpokenLanguages, movie.PosterPath, movie.BackdropPath, movie.Homepage, movie.GUID, movie.OriginalTitle,
+		movie.ProductionCountries, movie.Rewatch, movie.Source, movie.UserID,
 	)
 	if err != nil {
 		return fmt.Errorf("erro ao inserir filme: %w", err)
 	}
 
+	// Um filme registrado no diário deixa de fazer sentido na watchlist.
+	if movie.TMDBId != "" {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM public.watchlist WHERE user_id=$1 AND tmdb_id=$2`, movie.UserID, movie.TMDBId); err != nil {
+			return fmt.Errorf("erro ao remover filme da watchlist: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("erro ao confirmar inserção do filme: %w", err)
+	}
+
 	return nil
 }
 
-func (r *MovieRepository) GetMovieByGUID(guid string) (*models.Movie, error) {
+func (r *MovieRepository) GetMovieByGUID(userID int, guid string) (*models.Movie, error) {
 	var movie models.Movie
-	query := `SELECT * FROM public.filmes WHERE guid=$1`
+	query := `SELECT ` + movieColumns + ` FROM public.filmes WHERE user_id=$1 AND guid=$2`
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	err := r.DB.QueryRowContext(ctx, query, guid).Scan(
-		&movie.ID, &movie.Title, &movie.Year, &movie.WatchedDate, &movie.MemberRating,
-		&movie.Description, &movie.IMDBRating, &movie.Genre, &movie.Plot, &movie.Director,
-		&movie.TMDBId, &movie.Runtime, &movie.ReleaseDate, &movie.Budget, &movie.Revenue,
-		&movie.Tagline, &movie.Status, &movie.OriginalLanguage, &movie.ProductionCompanies,
-		&movie.SpokenLanguages, &movie.PosterPath, &movie.BackdropPath, &movie.Homepage, &movie.GUID,
-	)
+	err := scanMovie(r.DB.QueryRowContext(ctx, query, userID, guid), &movie)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, sql.ErrNoRows
@@ -86,14 +117,276 @@ func (r *MovieRepository) GetMovieByGUID(guid string) (*models.Movie, error) {
 	return &movie, nil
 }
 
-func (r *MovieRepository) GetAllMovies() ([]models.Movie, error) {
+func (r *MovieRepository) GetRecentMovies(userID, limit int) ([]models.Movie, error) {
+	movies := []models.Movie{}
+	query := `
+		SELECT ` + movieColumns + `
+		FROM public.filmes
+		WHERE user_id=$1
+		ORDER BY watched_date DESC NULLS LAST, created_at DESC
+		LIMIT $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.DB.QueryContext(ctx, query, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar filmes recentes: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var movie models.Movie
+		if err := scanMovie(rows, &movie); err != nil {
+			return nil, fmt.Errorf("erro ao ler filme: %w", err)
+		}
+		movies = append(movies, movie)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre os filmes recentes: %w", err)
+	}
+
+	return movies, nil
+}
+
+func (r *MovieRepository) GetDiaryVersion(userID int) (int, time.Time, error) {
+	var count int
+	var lastModified sql.NullTime
+	query := `SELECT count(*), max(updated_at) FROM public.filmes WHERE user_id=$1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := r.DB.QueryRowContext(ctx, query, userID).Scan(&count, &lastModified); err != nil {
+		return 0, time.Time{}, fmt.Errorf("erro ao verificar versão do diário: %w", err)
+	}
+
+	return count, lastModified.Time, nil
+}
+
+func (r *MovieRepository) GetMovies(userID int, filter MovieFilter) ([]models.Movie, error) {
+	movies := []models.Movie{}
+	query := `
+		SELECT ` + movieColumns + `
+		FROM public.filmes
+		WHERE user_id = $1
+			AND ($2 = '' OR EXISTS (
+				SELECT 1 FROM public.movie_tags mt
+				JOIN public.tags t ON t.id = mt.tag_id
+				WHERE mt.movie_id = filmes.id AND lower(t.name) = lower($2)
+			))
+			AND ($3 = 0 OR EXISTS (
+				SELECT 1 FROM public.list_items li
+				WHERE li.list_id = $3 AND li.tmdb_id = filmes.tmdb_id
+			))
+		ORDER BY watched_date DESC NULLS LAST`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.DB.QueryContext(ctx, query, userID, filter.Tag, filter.ListID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar filmes: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var movie models.Movie
+		if err := scanMovie(rows, &movie); err != nil {
+			return nil, fmt.Errorf("erro ao ler filme: %w", err)
+		}
+		movies = append(movies, movie)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre os filmes: %w", err)
+	}
+
+	return movies, nil
+}
+
+// Filmes com data de visualização, opcionalmente restritos a um ano (0 = todos).
+func (r *MovieRepository) GetWatchedMovies(userID, year int) ([]models.Movie, error) {
+	movies := []models.Movie{}
+	query := `
+		SELECT ` + movieColumns + `
+		FROM public.filmes
+		WHERE user_id = $1 AND watched_date IS NOT NULL
+			AND ($2 = 0 OR EXTRACT(YEAR FROM watched_date) = $2)
+		ORDER BY watched_date, id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.DB.QueryContext(ctx, query, userID, year)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar filmes assistidos: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var movie models.Movie
+		if err := scanMovie(rows, &movie); err != nil {
+			return nil, fmt.Errorf("erro ao ler filme: %w", err)
+		}
+		movies = append(movies, movie)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre os filmes: %w", err)
+	}
+
+	return movies, nil
+}
+
+func (r *MovieRepository) StreamMovies(ctx context.Context, userID int, fn func(*models.Movie) error) error {
+	query := `SELECT ` + movieColumns + ` FROM public.filmes WHERE user_id=$1 ORDER BY watched_date DESC NULLS LAST, id DESC`
+
+	rows, err := r.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		return fmt.Errorf("erro ao buscar filmes: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var movie models.Movie
+		if err := scanMovie(rows, &movie); err != nil {
+			return fmt.Errorf("erro ao ler filme: %w", err)
+		}
+		if err := fn(&movie); err != nil {
+			return err
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("erro ao iterar sobre os filmes: %w", err)
+	}
+
+	return nil
+}
+
+func (r *MovieRepository) UpdateMovie(movie *models.Movie) error {
+	query := `
+		UPDATE public.filmes
+		SET watched_date=$3, member_rating=$4, description=$5, rewatch=$6, updated_at=now()
+		WHERE guid=$1 AND user_id=$2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := r.DB.ExecContext(ctx, query,
+		movie.GUID, movie.UserID, toNullString(movie.WatchedDate), movie.MemberRating, movie.Description, movie.Rewatch,
+	)
+	if err != nil {
+		return fmt.Errorf("erro ao atualizar filme: %w", err)
+	}
+
+	return expectAffected(result)
+}
+
+// Atualiza apenas os campos vindos do TMDb, preservando os dados do diário.
+func (r *MovieRepository) UpdateMovieTMDBInfo(movie *models.Movie) error {
+	query := `
+		UPDATE public.filmes
+		SET title=$2, year=$3, imdb_rating=$4, genre=$5, plot=$6, director=$7, tmdb_id=$8, runtime=$9,
+			release_date=$10, budget=$11, revenue=$12, tagline=$13, status=$14, original_language=$15,
+			production_companies=$16, spoken_languages=$17, poster_path=$18, backdrop_path=$19, homepage=$20,
+			original_title=$21, production_countries=$22, updated_at=now()
+		WHERE id=$1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := r.DB.ExecContext(ctx, query,
+		movie.ID, movie.Title, movie.Year, movie.IMDBRating, movie.Genre, movie.Plot, movie.Director, movie.TMDBId,
+		movie.Runtime, toNullString(movie.ReleaseDate), movie.Budget, movie.Revenue, movie.Tagline, movie.Status,
+		movie.OriginalLanguage, movie.ProductionCompanies, movie.SpokenLanguages, movie.PosterPath, movie.BackdropPath,
+		movie.Homepage, movie.OriginalTitle, movie.ProductionCountries,
+	)
+	if err != nil {
+		return fmt.Errorf("erro ao atualizar dados do TMDb do filme: %w", err)
+	}
+
+	return expectAffected(result)
+}
+
+// Filmes sem TMDb id, sem dados básicos ou sem créditos salvos; com all=true, todos.
+func (r *MovieRepository) GetMoviesToEnrich(all bool, limit int) ([]models.Movie, error) {
+	movies := []models.Movie{}
+	query := `
+		SELECT ` + movieColumns + `
+		FROM public.filmes
+		WHERE $1 OR tmdb_id = '' OR plot = '' OR poster_path = ''
+			OR NOT EXISTS (SELECT 1 FROM public.movie_credits_index c WHERE c.tmdb_id = filmes.tmdb_id)
+		ORDER BY watched_date DESC NULLS LAST, id DESC
+		LIMIT $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.DB.QueryContext(ctx, query, all, limit)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar filmes para enriquecer: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var movie models.Movie
+		if err := scanMovie(rows, &movie); err != nil {
+			return nil, fmt.Errorf("erro ao ler filme: %w", err)
+		}
+		movies = append(movies, movie)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre os filmes: %w", err)
+	}
+
+	return movies, nil
+}
+
+// Indica se o filme já foi registrado no diário na data informada, qualquer que seja a origem.
+func (r *MovieRepository) MovieWatchedOn(userID int, tmdbId, watchedDate string) (bool, error) {
+	var exists bool
+	query := `
+		SELECT EXISTS(
+			SELECT 1 FROM public.filmes
+			WHERE user_id=$1 AND tmdb_id=$2 AND watched_date IS NOT DISTINCT FROM $3::date
+		)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := r.DB.QueryRowContext(ctx, query, userID, tmdbId, toNullString(watchedDate)).Scan(&exists); err != nil {
+		return false, fmt.Errorf("erro ao verificar filme no diário: %w", err)
+	}
+
+	return exists, nil
+}
+
+func (r *MovieRepository) DeleteMovie(userID int, guid string) error {
+	query := `DELETE FROM public.filmes WHERE guid=$1 AND user_id=$2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := r.DB.ExecContext(ctx, query, guid, userID)
+	if err != nil {
+		return fmt.Errorf("erro ao remover filme: %w", err)
+	}
+
+	return expectAffected(result)
+}
+
+func (r *MovieRepository) GetAllMovies(userID int) ([]models.Movie, error) {
 	var movies []models.Movie
-	query := `SELECT * FROM public.filmes ORDER BY watched_date DESC NULLS LAST`
+	query := `SELECT ` + movieColumns + ` FROM public.filmes WHERE user_id=$1 ORDER BY watched_date DESC NULLS LAST`
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	rows, err := r.DB.QueryContext(ctx, query)
+	rows, err := r.DB.QueryContext(ctx, query, userID)
 	if err != nil {
 		return nil, fmt.Errorf("erro ao buscar todos os filmes: %w", err)
 	}
@@ -101,13 +394,7 @@ func (r *MovieRepository) GetAllMovies() ([]models.Movie, error) {
 
 	for rows.Next() {
 		var movie models.Movie
-		err = rows.Scan(
-			&movie.ID, &movie.Title, &movie.Year, &movie.WatchedDate, &movie.MemberRating,
-			&movie.Description, &movie.IMDBRating, &movie.Genre, &movie.Plot, &movie.Director,
-			&movie.TMDBId, &movie.Runtime, &movie.ReleaseDate, &movie.Budget, &movie.Revenue,
-			&movie.Tagline, &movie.Status, &movie.OriginalLanguage, &movie.ProductionCompanies,
-			&movie.SpokenLanguages, &movie.PosterPath, &movie.BackdropPath, &movie.Homepage, &movie.GUID,
-		)
+		err = scanMovie(rows, &movie)
 		if err != nil {
 			return nil, fmt.Errorf("erro ao ler filme: %w", err)
 		}
@@ -121,6 +408,30 @@ func (r *MovieRepository) GetAllMovies() ([]models.Movie, error) {
 	return movies, nil
 }
 
+func scanMovie(row rowScanner, movie *models.Movie, extra ...any) error {
+	dest := []any{
+		&movie.ID, &movie.Title, &movie.Year, &movie.WatchedDate, &movie.MemberRating,
+		&movie.Description, &movie.IMDBRating, &movie.Genre, &movie.Plot, &movie.Director,
+		&movie.TMDBId, &movie.Runtime, &movie.ReleaseDate, &movie.Budget, &movie.Revenue,
+		&movie.Tagline, &movie.Status, &movie.OriginalLanguage, &movie.ProductionCompanies,
+		&movie.SpokenLanguages, &movie.PosterPath, &movie.BackdropPath, &movie.Homepage, &movie.GUID,
+		&movie.OriginalTitle, &movie.ProductionCountries, &movie.Rewatch, &movie.Source,
+		&movie.UpdatedAt, &movie.UserID,
+	}
+	return row.Scan(append(dest, extra...)...)
+}
+
+func expectAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("erro ao verificar linhas afetadas: %w", err)
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
 func toNullString(value string) sql.NullString {
 	if value == "" {
 		return sql.NullString{}
@@ -128,9 +439,9 @@ func toNullString(value string) sql.NullString {
 	return sql.NullString{String: value, Valid: true}
 }
 
-func CheckMovieExists(db *sql.DB, guid string) (bool, error) {
+func CheckMovieExists(db *sql.DB, userID int, guid string) (bool, error) {
 	repo := NewMovieRepository(db)
-	return repo.CheckMovieExists(guid)
+	return repo.CheckMovieExists(userID, guid)
 }
 
 func InsertMovie(db *sql.DB, movie *models.Movie) error {
@@ -138,7 +449,62 @@ func InsertMovie(db *sql.DB, movie *models.Movie) error {
 	return repo.InsertMovie(movie)
 }
 
-func GetMovieByGUID(db *sql.DB, guid string) (*models.Movie, error) {
+func GetMovieByGUID(db *sql.DB, userID int, guid string) (*models.Movie, error) {
+	repo := NewMovieRepository(db)
+	return repo.GetMovieByGUID(userID, guid)
+}
+
+func GetAllMovies(db *sql.DB, userID int) ([]models.Movie, error) {
+	repo := NewMovieRepository(db)
+	return repo.GetAllMovies(userID)
+}
+
+func UpdateMovie(db *sql.DB, movie *models.Movie) error {
+	repo := NewMovieRepository(db)
+	return repo.UpdateMovie(movie)
+}
+
+func DeleteMovie(db *sql.DB, userID int, guid string) error {
+	repo := NewMovieRepository(db)
+	return repo.DeleteMovie(userID, guid)
+}
+
+func GetMovies(db *sql.DB, userID int, filter MovieFilter) ([]models.Movie, error) {
+	repo := NewMovieRepository(db)
+	return repo.GetMovies(userID, filter)
+}
+
+func StreamMovies(ctx context.Context, db *sql.DB, userID int, fn func(*models.Movie) error) error {
+	repo := NewMovieRepository(db)
+	return repo.StreamMovies(ctx, userID, fn)
+}
+
+func GetRecentMovies(db *sql.DB, userID, limit int) ([]models.Movie, error) {
+	repo := NewMovieRepository(db)
+	return repo.GetRecentMovies(userID, limit)
+}
+
+func GetDiaryVersion(db *sql.DB, userID int) (int, time.Time, error) {
+	repo := NewMovieRepository(db)
+	return repo.GetDiaryVersion(userID)
+}
+
+func GetWatchedMovies(db *sql.DB, userID, year int) ([]models.Movie, error) {
+	repo := NewMovieRepository(db)
+	return repo.GetWatchedMovies(userID, year)
+}
+
+func UpdateMovieTMDBInfo(db *sql.DB, movie *models.Movie) error {
+	repo := NewMovieRepository(db)
+	return repo.UpdateMovieTMDBInfo(movie)
+}
+
+func GetMoviesToEnrich(db *sql.DB, all bool, limit int) ([]models.Movie, error) {
+	repo := NewMovieRepository(db)
+	return repo.GetMoviesToEnrich(all, limit)
+}
+
+func MovieWatchedOn(db *sql.DB, userID int, tmdbId, watchedDate string) (bool, error) {
 	repo := NewMovieRepository(db)
-	return repo.GetMovieByGUID(guid)
+	return repo.MovieWatchedOn(userID, tmdbId, watchedDate)
 }
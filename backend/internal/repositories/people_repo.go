@@ -0,0 +1,163 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"letterboxd-viewer-backend/internal/models"
+	"time"
+)
+
+var personRoleFilters = map[string]string{
+	"actor":           `SELECT tmdb_id, person_id FROM public.movie_cast`,
+	"director":        `SELECT tmdb_id, person_id FROM public.movie_crew WHERE job = 'Director'`,
+	"writer":          `SELECT tmdb_id, person_id FROM public.movie_crew WHERE department = 'Writing'`,
+	"cinematographer": `SELECT tmdb_id, person_id FROM public.movie_crew WHERE job = 'Director of Photography'`,
+	"composer":        `SELECT tmdb_id, person_id FROM public.movie_crew WHERE job = 'Original Music Composer'`,
+	"producer":        `SELECT tmdb_id, person_id FROM public.movie_crew WHERE job = 'Producer'`,
+}
+
+type PeopleRepository struct {
+	DB *sql.DB
+}
+
+func NewPeopleRepository(db *sql.DB) *PeopleRepository {
+	return &PeopleRepository{
+		DB: db,
+	}
+}
+
+func IsValidPersonRole(role string) bool {
+	_, ok := personRoleFilters[role]
+	return ok
+}
+
+func (r *PeopleRepository) GetPerson(personId int) (*models.Person, error) {
+	var person models.Person
+	query := `SELECT id, name, gender, profile_path FROM public.people WHERE id=$1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := r.DB.QueryRowContext(ctx, query, personId).Scan(&person.ID, &person.Name, &person.Gender, &person.ProfilePath)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("erro ao buscar pessoa: %w", err)
+	}
+
+	return &person, nil
+}
+
+func (r *PeopleRepository) GetFilmography(userID, personId int) ([]models.PersonFilm, error) {
+	films := []models.PersonFilm{}
+	query := `
+		WITH roles AS (
+			SELECT tmdb_id, 'Acting' AS department, 'Actor' AS job, character, ord
+			FROM public.movie_cast
+			WHERE person_id = $1
+			UNION ALL
+			SELECT tmdb_id, department, job, '' AS character, 1000 AS ord
+			FROM public.movie_crew
+			WHERE person_id = $1
+		), grouped AS (
+			SELECT tmdb_id,
+				json_agg(json_build_object('department', department, 'job', job, 'character', character) ORDER BY ord, job) AS roles
+			FROM roles
+			GROUP BY tmdb_id
+		)
+		SELECT ` + movieColumns + `, grouped.roles
+		FROM public.filmes
+		JOIN grouped ON grouped.tmdb_id = filmes.tmdb_id
+		WHERE filmes.user_id = $2
+		ORDER BY watched_date DESC NULLS LAST`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.DB.QueryContext(ctx, query, personId, userID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar filmografia: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var film models.PersonFilm
+		var roles []byte
+		if err := scanMovie(rows, &film.Movie, &roles); err != nil {
+			return nil, fmt.Errorf("erro ao ler filmografia: %w", err)
+		}
+		if err := json.Unmarshal(roles, &film.Roles); err != nil {
+			return nil, fmt.Errorf("erro ao decodificar funções: %w", err)
+		}
+		films = append(films, film)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre a filmografia: %w", err)
+	}
+
+	return films, nil
+}
+
+func (r *PeopleRepository) GetTopPeople(userID int, role string, limit int) ([]models.PersonRanking, error) {
+	rankings := []models.PersonRanking{}
+	filter, ok := personRoleFilters[role]
+	if !ok {
+		return nil, fmt.Errorf("função desconhecida: %s", role)
+	}
+
+	query := `
+		WITH credits AS (` + filter + `)
+		SELECT p.id, p.name, p.profile_path, count(DISTINCT f.id), avg(` + memberRatingExpr + `)
+		FROM (SELECT DISTINCT tmdb_id, person_id FROM credits) c
+		JOIN public.filmes f ON f.tmdb_id = c.tmdb_id
+		JOIN public.people p ON p.id = c.person_id
+		WHERE f.user_id = $2
+		GROUP BY p.id, p.name, p.profile_path
+		ORDER BY count(DISTINCT f.id) DESC, avg(` + memberRatingExpr + `) DESC NULLS LAST, p.name
+		LIMIT $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.DB.QueryContext(ctx, query, limit, userID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar ranking de pessoas: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var ranking models.PersonRanking
+		var average sql.NullFloat64
+		if err := rows.Scan(&ranking.ID, &ranking.Name, &ranking.ProfilePath, &ranking.FilmCount, &average); err != nil {
+			return nil, fmt.Errorf("erro ao ler ranking de pessoas: %w", err)
+		}
+		ranking.AverageRating = nullFloatPtr(average)
+		rankings = append(rankings, ranking)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre o ranking de pessoas: %w", err)
+	}
+
+	return rankings, nil
+}
+
+func GetPerson(db *sql.DB, personId int) (*models.Person, error) {
+	repo := NewPeopleRepository(db)
+	return repo.GetPerson(personId)
+}
+
+func GetFilmography(db *sql.DB, userID, personId int) ([]models.PersonFilm, error) {
+	repo := NewPeopleRepository(db)
+	return repo.GetFilmography(userID, personId)
+}
+
+func GetTopPeople(db *sql.DB, userID int, role string, limit int) ([]models.PersonRanking, error) {
+	repo := NewPeopleRepository(db)
+	return repo.GetTopPeople(userID, role, limit)
+}
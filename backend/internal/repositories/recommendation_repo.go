@@ -0,0 +1,186 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"letterboxd-viewer-backend/internal/models"
+)
+
+type RecommendationRepository struct {
+	DB *sql.DB
+}
+
+func NewRecommendationRepository(db *sql.DB) *RecommendationRepository {
+	return &RecommendationRepository{
+		DB: db,
+	}
+}
+
+// GetCandidates agrupa por filme as notas dos críticos com correlação
+// positiva com o usuário, ignorando os filmes que já estão no diário dele.
+// Gênero, duração e pôster vêm de qualquer diário que já tenha o filme ou,
+// se ninguém o registrou, dos dados buscados ao sincronizar os críticos.
+func (r *RecommendationRepository) GetCandidates(userID int) ([]models.Recommendation, error) {
+	candidates := []models.Recommendation{}
+	query := `
+		WITH similar AS (
+			SELECT s.critic, c.display_name, ` + similarityScoreExpr + ` AS weight, s.agreement
+			FROM public.critic_similarity s
+			JOIN public.critics c ON c.username = s.critic
+			WHERE s.user_id = $1 AND s.pearson > 0
+		), latest AS (
+			SELECT DISTINCT ON (e.critic, e.tmdb_id) e.critic, e.tmdb_id, e.title, e.year,
+				` + memberRatingExpr + ` AS rating
+			FROM public.critic_entries e
+			JOIN similar ON similar.critic = e.critic
+			WHERE e.tmdb_id <> '' AND ` + memberRatingExpr + ` > 0
+				AND NOT EXISTS (
+					SELECT 1 FROM public.filmes f WHERE f.user_id = $1 AND f.tmdb_id = e.tmdb_id
+				)
+			ORDER BY e.critic, e.tmdb_id, e.watched_date DESC NULLS LAST, e.id DESC
+		)
+		SELECT l.tmdb_id, l.title, l.year, coalesce(m.genre, e.genre, ''), coalesce(m.runtime, e.runtime, 0),
+			coalesce(m.poster_path, e.poster_path, ''),
+			l.critic, s.display_name, l.rating, s.weight, s.agreement
+		FROM latest l
+		JOIN similar s ON s.critic = l.critic
+		LEFT JOIN LATERAL (
+			SELECT genre, runtime, poster_path
+			FROM public.filmes
+			WHERE tmdb_id = l.tmdb_id AND genre <> ''
+			LIMIT 1
+		) m ON TRUE
+		LEFT JOIN LATERAL (
+			SELECT genre, runtime, poster_path
+			FROM public.critic_entries
+			WHERE tmdb_id = l.tmdb_id AND metadata_fetched_at IS NOT NULL
+			LIMIT 1
+		) e ON TRUE
+		ORDER BY l.tmdb_id, s.weight DESC`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar candidatos a recomendação: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var movie models.Recommendation
+		var rating models.CriticRating
+		var agreement sql.NullFloat64
+		err := rows.Scan(
+			&movie.TMDBId, &movie.Title, &movie.Year, &movie.Genre, &movie.Runtime, &movie.PosterPath,
+			&rating.Critic, &rating.DisplayName, &rating.Rating, &rating.Similarity, &agreement,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler candidato a recomendação: %w", err)
+		}
+		rating.Agreement = nullFloatPtr(agreement)
+
+		last := len(candidates) - 1
+		if last < 0 || candidates[last].TMDBId != movie.TMDBId {
+			candidates = append(candidates, movie)
+			last++
+		}
+		candidates[last].Ratings = append(candidates[last].Ratings, rating)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre os candidatos a recomendação: %w", err)
+	}
+
+	return candidates, nil
+}
+
+// GetSimilarCandidates lista os filmes de todos os diários, exceto o de
+// referência, que estão em tmdbIds ou têm algum dos gêneros (em minúsculas).
+// Os que estão no diário do usuário vêm marcados como vistos, com o GUID da
+// entrada mais recente.
+func (r *RecommendationRepository) GetSimilarCandidates(userID int, tmdbId string, tmdbIds, genres []string) ([]models.SimilarMovie, error) {
+	candidates := []models.SimilarMovie{}
+	query := `
+		SELECT DISTINCT ON (tmdb_id) tmdb_id, title, year, coalesce(genre, ''), coalesce(poster_path, ''),
+			CASE WHEN user_id = $1 THEN guid ELSE '' END
+		FROM public.filmes
+		WHERE tmdb_id <> '' AND tmdb_id <> $2
+			AND (tmdb_id = ANY($3::text[]) OR regexp_split_to_array(lower(coalesce(genre, '')), '\s*,\s*') && $4::text[])
+		ORDER BY tmdb_id, (user_id = $1) DESC, watched_date DESC NULLS LAST, id DESC`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.DB.QueryContext(ctx, query, userID, tmdbId, tmdbIds, genres)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar candidatos a filmes parecidos: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var movie models.SimilarMovie
+		err := rows.Scan(&movie.TMDBId, &movie.Title, &movie.Year, &movie.Genre, &movie.PosterPath, &movie.GUID)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler candidato a filme parecido: %w", err)
+		}
+		movie.Watched = movie.GUID != ""
+		candidates = append(candidates, movie)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre os candidatos a filmes parecidos: %w", err)
+	}
+
+	return candidates, nil
+}
+
+// GetSharedFeatures devolve as pessoas da direção, do elenco principal (as
+// topCast primeiras posições) e as palavras-chave que outros filmes têm em
+// comum com o filme de referência.
+func (r *RecommendationRepository) GetSharedFeatures(tmdbId string, topCast int) ([]models.SharedFeature, error) {
+	features := []models.SharedFeature{}
+	query := `
+		SELECT c.tmdb_id, '` + models.SharedDirector + `', p.name
+		FROM public.movie_crew c
+		JOIN public.movie_crew s ON s.person_id = c.person_id AND s.tmdb_id = $1 AND s.job = 'Director'
+		JOIN public.people p ON p.id = c.person_id
+		WHERE c.tmdb_id <> $1 AND c.job = 'Director'
+		UNION
+		SELECT c.tmdb_id, '` + models.SharedCast + `', p.name
+		FROM public.movie_cast c
+		JOIN public.movie_cast s ON s.person_id = c.person_id AND s.tmdb_id = $1 AND s.ord < $2
+		JOIN public.people p ON p.id = c.person_id
+		WHERE c.tmdb_id <> $1 AND c.ord < $2
+		UNION
+		SELECT c.tmdb_id, '` + models.SharedKeyword + `', c.name
+		FROM public.movie_keywords c
+		JOIN public.movie_keywords s ON s.keyword_id = c.keyword_id AND s.tmdb_id = $1
+		WHERE c.tmdb_id <> $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.DB.QueryContext(ctx, query, tmdbId, topCast)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar metadados em comum: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var feature models.SharedFeature
+		if err := rows.Scan(&feature.TMDBId, &feature.Kind, &feature.Name); err != nil {
+			return nil, fmt.Errorf("erro ao ler metadado em comum: %w", err)
+		}
+		features = append(features, feature)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre os metadados em comum: %w", err)
+	}
+
+	return features, nil
+}
@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"letterboxd-viewer-backend/internal/models"
+	"time"
+)
+
+type SearchRepository struct {
+	DB *sql.DB
+}
+
+func NewSearchRepository(db *sql.DB) *SearchRepository {
+	return &SearchRepository{
+		DB: db,
+	}
+}
+
+func (r *SearchRepository) SearchMovies(userID int, term string, limit int) ([]models.SearchResult, error) {
+	results := []models.SearchResult{}
+	query := `
+		WITH q AS (
+			SELECT websearch_to_tsquery('portuguese', $1)
+				|| websearch_to_tsquery('english', $1)
+				|| websearch_to_tsquery('simple', $1) AS query
+		)
+		SELECT ` + movieColumns + `,
+			ts_rank_cd(search_vector, q.query)
+				+ coalesce((
+					SELECT ts_rank_cd(ci.cast_vector, q.query)
+					FROM public.movie_credits_index ci
+					WHERE ci.tmdb_id = filmes.tmdb_id
+				), 0)
+				+ greatest(similarity(title, $1), similarity(original_title, $1)) AS rank,
+			ts_headline(
+				'simple',
+				concat_ws(' … ', nullif(plot, ''), nullif(tagline, ''),
+					nullif(regexp_replace(description, '<[^>]*>', ' ', 'g'), '')),
+				q.query,
+				'StartSel=<mark>, StopSel=</mark>, MaxWords=35, MinWords=15, MaxFragments=2'
+			) AS snippet
+		FROM public.filmes
+		CROSS JOIN q
+		WHERE user_id = $3
+			AND (search_vector @@ q.query
+				OR tmdb_id IN (
+					SELECT ci.tmdb_id
+					FROM public.movie_credits_index ci, q
+					WHERE ci.cast_vector @@ q.query
+				)
+				OR title % $1
+				OR original_title % $1)
+		ORDER BY rank DESC, watched_date DESC NULLS LAST
+		LIMIT $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := r.DB.QueryContext(ctx, query, term, limit, userID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar filmes: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var result models.SearchResult
+		if err := scanMovie(rows, &result.Movie, &result.Rank, &result.Snippet); err != nil {
+			return nil, fmt.Errorf("erro ao ler resultado da busca: %w", err)
+		}
+		results = append(results, result)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre os resultados da busca: %w", err)
+	}
+
+	return results, nil
+}
+
+func SearchMovies(db *sql.DB, userID int, term string, limit int) ([]models.SearchResult, error) {
+	repo := NewSearchRepository(db)
+	return repo.SearchMovies(userID, term, limit)
+}
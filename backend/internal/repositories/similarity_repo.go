@@ -0,0 +1,139 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"letterboxd-viewer-backend/internal/models"
+	"time"
+)
+
+// O score reduz a correlação de críticos com poucos filmes em comum: com 10
+// filmes em comum, vale metade da correlação de Pearson.
+const similarityScoreExpr = `(coalesce(s.pearson, 0) * s.overlap / (s.overlap + 10.0))`
+
+type SimilarityRepository struct {
+	DB *sql.DB
+}
+
+func NewSimilarityRepository(db *sql.DB) *SimilarityRepository {
+	return &SimilarityRepository{
+		DB: db,
+	}
+}
+
+// GetSharedRatings devolve os pares (minha nota, nota do crítico) dos filmes
+// que os dois avaliaram, usando a entrada mais recente de cada um por filme.
+func (r *SimilarityRepository) GetSharedRatings(userID int, critic string) ([][2]float64, error) {
+	var pairs [][2]float64
+	query := `
+		WITH mine AS (
+			SELECT DISTINCT ON (tmdb_id) tmdb_id, ` + memberRatingExpr + ` AS rating
+			FROM public.filmes
+			WHERE user_id = $1 AND tmdb_id <> '' AND ` + memberRatingExpr + ` > 0
+			ORDER BY tmdb_id, watched_date DESC NULLS LAST, id DESC
+		), theirs AS (
+			SELECT DISTINCT ON (tmdb_id) tmdb_id, ` + memberRatingExpr + ` AS rating
+			FROM public.critic_entries
+			WHERE critic = $2 AND tmdb_id <> '' AND ` + memberRatingExpr + ` > 0
+			ORDER BY tmdb_id, watched_date DESC NULLS LAST, id DESC
+		)
+		SELECT m.rating, t.rating
+		FROM mine m
+		JOIN theirs t ON t.tmdb_id = m.tmdb_id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.DB.QueryContext(ctx, query, userID, critic)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar notas em comum: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var pair [2]float64
+		if err := rows.Scan(&pair[0], &pair[1]); err != nil {
+			return nil, fmt.Errorf("erro ao ler notas em comum: %w", err)
+		}
+		pairs = append(pairs, pair)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre as notas em comum: %w", err)
+	}
+
+	return pairs, nil
+}
+
+// SaveSimilarity não faz nada se o usuário deixou de acompanhar o crítico
+// durante o cálculo.
+func (r *SimilarityRepository) SaveSimilarity(userID int, sim *models.CriticSimilarity) error {
+	query := `
+		INSERT INTO public.critic_similarity (user_id, critic, overlap, pearson, spearman, mean_abs_diff, agreement, computed_at)
+		SELECT $1, $2, $3, $4, $5, $6, $7, now()
+		WHERE EXISTS (SELECT 1 FROM public.critic_follows WHERE user_id = $1 AND critic = $2)
+		ON CONFLICT (user_id, critic) DO UPDATE SET
+			overlap = EXCLUDED.overlap,
+			pearson = EXCLUDED.pearson,
+			spearman = EXCLUDED.spearman,
+			mean_abs_diff = EXCLUDED.mean_abs_diff,
+			agreement = EXCLUDED.agreement,
+			computed_at = EXCLUDED.computed_at`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.DB.ExecContext(ctx, query, userID, sim.Critic, sim.Overlap, sim.Pearson, sim.Spearman, sim.MeanAbsDiff, sim.Agreement)
+	if err != nil {
+		return fmt.Errorf("erro ao salvar similaridade com %s: %w", sim.Critic, err)
+	}
+
+	return nil
+}
+
+// GetSimilarities lista os críticos acompanhados do mais ao menos parecido.
+// Críticos ainda sem cálculo aparecem no fim, com sobreposição zero.
+func (r *SimilarityRepository) GetSimilarities(userID, minOverlap int) ([]models.CriticSimilarity, error) {
+	similarities := []models.CriticSimilarity{}
+	query := `
+		SELECT f.critic, c.display_name, coalesce(s.overlap, 0), s.pearson, s.spearman, s.mean_abs_diff, s.agreement,
+			coalesce(` + similarityScoreExpr + `, 0) AS score, coalesce(s.computed_at, f.created_at)
+		FROM public.critic_follows f
+		JOIN public.critics c ON c.username = f.critic
+		LEFT JOIN public.critic_similarity s ON s.user_id = f.user_id AND s.critic = f.critic
+		WHERE f.user_id = $1 AND coalesce(s.overlap, 0) >= $2
+		ORDER BY score DESC, coalesce(s.overlap, 0) DESC, f.critic`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.DB.QueryContext(ctx, query, userID, minOverlap)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar similaridades: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var sim models.CriticSimilarity
+		var pearson, spearman, meanAbsDiff, agreement sql.NullFloat64
+		err := rows.Scan(
+			&sim.Critic, &sim.DisplayName, &sim.Overlap, &pearson, &spearman, &meanAbsDiff, &agreement,
+			&sim.Score, &sim.ComputedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler similaridade: %w", err)
+		}
+		sim.Pearson = nullFloatPtr(pearson)
+		sim.Spearman = nullFloatPtr(spearman)
+		sim.MeanAbsDiff = nullFloatPtr(meanAbsDiff)
+		sim.Agreement = nullFloatPtr(agreement)
+		similarities = append(similarities, sim)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre as similaridades: %w", err)
+	}
+
+	return similarities, nil
+}
@@ -0,0 +1,312 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"letterboxd-viewer-backend/internal/models"
+	"time"
+)
+
+const (
+	// $1 é o dono do diário; $2 e $3 são as datas inicial e final (opcionais)
+	// do filtro por watched_date.
+	watchedDateFilter = `user_id = $1 AND ($2::date IS NULL OR watched_date >= $2::date) AND ($3::date IS NULL OR watched_date <= $3::date)`
+
+	memberRatingExpr = `(CASE WHEN member_rating ~ '^[0-9]+(\.[0-9]+)?$' THEN member_rating::numeric END)`
+
+	// imdb_rating guarda o vote_average do TMDb, numa escala de 0 a 10.
+	tmdbRatingExpr = `(CASE WHEN imdb_rating ~ '^[0-9]+(\.[0-9]+)?$' AND imdb_rating::numeric > 0 THEN imdb_rating::numeric END)`
+)
+
+type DateRange struct {
+	From string
+	To   string
+}
+
+func (d DateRange) args(userID int) []any {
+	return []any{userID, toNullString(d.From), toNullString(d.To)}
+}
+
+type StatsRepository struct {
+	DB *sql.DB
+}
+
+func NewStatsRepository(db *sql.DB) *StatsRepository {
+	return &StatsRepository{
+		DB: db,
+	}
+}
+
+func (r *StatsRepository) GetStats(userID int, dateRange DateRange) (*models.Stats, error) {
+	stats := &models.Stats{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	args := dateRange.args(userID)
+
+	err := r.DB.QueryRowContext(ctx, `
+		SELECT count(*), coalesce(sum(runtime), 0) / 60.0
+		FROM public.filmes
+		WHERE `+watchedDateFilter, args...).Scan(&stats.TotalMovies, &stats.TotalHours)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao calcular totais: %w", err)
+	}
+
+	countQueries := []struct {
+		target *[]models.CountStat
+		query  string
+	}{
+		{&stats.ByGenre, `
+			SELECT trim(g), count(*)
+			FROM public.filmes, unnest(string_to_array(nullif(genre, ''), ',')) AS g
+			WHERE ` + watchedDateFilter + `
+			GROUP BY trim(g)
+			ORDER BY count(*) DESC, trim(g)`},
+		{&stats.ByDecade, `
+			SELECT (left(year, 3) || '0s') AS decade, count(*)
+			FROM public.filmes
+			WHERE year ~ '^[0-9]{4}$' AND ` + watchedDateFilter + `
+			GROUP BY decade
+			ORDER BY decade`},
+		{&stats.ByLanguage, `
+			SELECT original_language, count(*)
+			FROM public.filmes
+			WHERE original_language <> '' AND ` + watchedDateFilter + `
+			GROUP BY original_language
+			ORDER BY count(*) DESC, original_language`},
+		{&stats.ByCountry, `
+			SELECT trim(c), count(*)
+			FROM public.filmes, unnest(string_to_array(nullif(production_countries, ''), ',')) AS c
+			WHERE ` + watchedDateFilter + `
+			GROUP BY trim(c)
+			ORDER BY count(*) DESC, trim(c)`},
+		{&stats.ByMonth, `
+			SELECT to_char(date_trunc('month', watched_date), 'YYYY-MM') AS month, count(*)
+			FROM public.filmes
+			WHERE watched_date IS NOT NULL AND ` + watchedDateFilter + `
+			GROUP BY month
+			ORDER BY month`},
+	}
+
+	for _, cq := range countQueries {
+		counts, err := r.queryCounts(ctx, cq.query, args)
+		if err != nil {
+			return nil, err
+		}
+		*cq.target = counts
+	}
+
+	stats.RatingDistribution, err = r.queryRatingDistribution(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+
+	stats.AverageRatingByGenre, err = r.queryAverages(ctx, `
+		SELECT trim(g), avg(`+memberRatingExpr+`), count(`+memberRatingExpr+`)
+		FROM public.filmes, unnest(string_to_array(nullif(genre, ''), ',')) AS g
+		WHERE `+watchedDateFilter+`
+		GROUP BY trim(g)
+		HAVING count(`+memberRatingExpr+`) > 0
+		ORDER BY avg(`+memberRatingExpr+`) DESC, trim(g)`, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+func (r *StatsRepository) queryCounts(ctx context.Context, query string, args []any) ([]models.CountStat, error) {
+	counts := []models.CountStat{}
+
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao calcular estatísticas: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var count models.CountStat
+		if err := rows.Scan(&count.Label, &count.Count); err != nil {
+			return nil, fmt.Errorf("erro ao ler estatística: %w", err)
+		}
+		counts = append(counts, count)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre as estatísticas: %w", err)
+	}
+
+	return counts, nil
+}
+
+func (r *StatsRepository) queryAverages(ctx context.Context, query string, args []any) ([]models.AverageStat, error) {
+	averages := []models.AverageStat{}
+
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao calcular médias: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var average models.AverageStat
+		if err := rows.Scan(&average.Label, &average.Average, &average.Count); err != nil {
+			return nil, fmt.Errorf("erro ao ler média: %w", err)
+		}
+		averages = append(averages, average)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre as médias: %w", err)
+	}
+
+	return averages, nil
+}
+
+func (r *StatsRepository) queryRatingDistribution(ctx context.Context, args []any) ([]models.RatingBucket, error) {
+	buckets := []models.RatingBucket{}
+
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT round(`+memberRatingExpr+` * 2) / 2 AS rating, count(*)
+		FROM public.filmes
+		WHERE `+memberRatingExpr+` IS NOT NULL AND `+watchedDateFilter+`
+		GROUP BY rating
+		ORDER BY rating`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao calcular distribuição de notas: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var bucket models.RatingBucket
+		if err := rows.Scan(&bucket.Rating, &bucket.Count); err != nil {
+			return nil, fmt.Errorf("erro ao ler distribuição de notas: %w", err)
+		}
+		buckets = append(buckets, bucket)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre a distribuição de notas: %w", err)
+	}
+
+	return buckets, nil
+}
+
+func (r *StatsRepository) GetRatingTimeSeries(userID int, dateRange DateRange, bucket string, window int) ([]models.RatingPoint, error) {
+	points := []models.RatingPoint{}
+	query := `
+		WITH rated AS (
+			SELECT date_trunc($4, watched_date)::date AS period,
+				` + memberRatingExpr + ` AS rating,
+				` + tmdbRatingExpr + ` / 2 AS tmdb_rating
+			FROM public.filmes
+			WHERE watched_date IS NOT NULL AND ` + watchedDateFilter + `
+		), buckets AS (
+			SELECT period,
+				count(*) AS total,
+				count(rating) AS rated,
+				sum(rating) AS rating_sum,
+				avg(tmdb_rating) AS avg_tmdb,
+				avg(rating - tmdb_rating) AS avg_delta
+			FROM rated
+			GROUP BY period
+		)
+		SELECT to_char(period, 'YYYY-MM-DD'),
+			total,
+			rated,
+			rating_sum / nullif(rated, 0),
+			sum(rating_sum) OVER w / nullif(sum(rated) OVER w, 0),
+			avg_tmdb,
+			avg_delta
+		FROM buckets
+		WINDOW w AS (ORDER BY period ROWS BETWEEN $5::int PRECEDING AND CURRENT ROW)
+		ORDER BY period`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	args := append(dateRange.args(userID), bucket, window-1)
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao calcular série temporal de notas: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var point models.RatingPoint
+		var average, rolling, tmdb, delta sql.NullFloat64
+		if err := rows.Scan(&point.Period, &point.Count, &point.RatedCount, &average, &rolling, &tmdb, &delta); err != nil {
+			return nil, fmt.Errorf("erro ao ler série temporal de notas: %w", err)
+		}
+		point.AverageRating = nullFloatPtr(average)
+		point.RollingAverage = nullFloatPtr(rolling)
+		point.AverageTMDBRating = nullFloatPtr(tmdb)
+		point.AverageDelta = nullFloatPtr(delta)
+		points = append(points, point)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre a série temporal de notas: %w", err)
+	}
+
+	return points, nil
+}
+
+func (r *StatsRepository) GetCalendarDays(userID int, dateRange DateRange) ([]models.CalendarDay, error) {
+	days := []models.CalendarDay{}
+	query := `
+		SELECT to_char(watched_date, 'YYYY-MM-DD') AS day, count(*), avg(` + memberRatingExpr + `)
+		FROM public.filmes
+		WHERE watched_date IS NOT NULL AND ` + watchedDateFilter + `
+		GROUP BY day
+		ORDER BY day`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.DB.QueryContext(ctx, query, dateRange.args(userID)...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar atividade diária: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var day models.CalendarDay
+		var average sql.NullFloat64
+		if err := rows.Scan(&day.Date, &day.Count, &average); err != nil {
+			return nil, fmt.Errorf("erro ao ler atividade diária: %w", err)
+		}
+		day.AverageRating = nullFloatPtr(average)
+		days = append(days, day)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre a atividade diária: %w", err)
+	}
+
+	return days, nil
+}
+
+func nullFloatPtr(value sql.NullFloat64) *float64 {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Float64
+}
+
+func GetStats(db *sql.DB, userID int, dateRange DateRange) (*models.Stats, error) {
+	repo := NewStatsRepository(db)
+	return repo.GetStats(userID, dateRange)
+}
+
+func GetRatingTimeSeries(db *sql.DB, userID int, dateRange DateRange, bucket string, window int) ([]models.RatingPoint, error) {
+	repo := NewStatsRepository(db)
+	return repo.GetRatingTimeSeries(userID, dateRange, bucket, window)
+}
+
+func GetCalendarDays(db *sql.DB, userID int, dateRange DateRange) ([]models.CalendarDay, error) {
+	repo := NewStatsRepository(db)
+	return repo.GetCalendarDays(userID, dateRange)
+}
@@ -0,0 +1,177 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"letterboxd-viewer-backend/internal/models"
+	"time"
+)
+
+type TagRepository struct {
+	DB *sql.DB
+}
+
+func NewTagRepository(db *sql.DB) *TagRepository {
+	return &TagRepository{
+		DB: db,
+	}
+}
+
+func (r *TagRepository) GetTags(userID int) ([]models.Tag, error) {
+	tags := []models.Tag{}
+	query := `
+		SELECT t.id, t.name, count(mt.movie_id)
+		FROM public.tags t
+		LEFT JOIN public.movie_tags mt ON mt.tag_id = t.id
+		WHERE t.user_id=$1
+		GROUP BY t.id
+		ORDER BY lower(t.name)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := r.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar tags: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tag models.Tag
+		if err := rows.Scan(&tag.ID, &tag.Name, &tag.Count); err != nil {
+			return nil, fmt.Errorf("erro ao ler tag: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre as tags: %w", err)
+	}
+
+	return tags, nil
+}
+
+func (r *TagRepository) GetMovieTags(movieId int) ([]string, error) {
+	tags := []string{}
+	query := `
+		SELECT t.name
+		FROM public.movie_tags mt
+		JOIN public.tags t ON t.id = mt.tag_id
+		WHERE mt.movie_id=$1
+		ORDER BY lower(t.name)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := r.DB.QueryContext(ctx, query, movieId)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar tags do filme: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, fmt.Errorf("erro ao ler tag do filme: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre as tags do filme: %w", err)
+	}
+
+	return tags, nil
+}
+
+// As tags pertencem ao dono do filme; cada usuário tem o seu vocabulário.
+func (r *TagRepository) SetMovieTags(userID, movieId int, names []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM public.movie_tags WHERE movie_id=$1`, movieId); err != nil {
+		return fmt.Errorf("erro ao limpar tags do filme: %w", err)
+	}
+
+	for _, name := range names {
+		if err := addMovieTag(ctx, tx, userID, movieId, name); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("erro ao confirmar tags do filme: %w", err)
+	}
+
+	return nil
+}
+
+func (r *TagRepository) AddMovieTag(userID, movieId int, name string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := addMovieTag(ctx, tx, userID, movieId, name); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("erro ao confirmar tag do filme: %w", err)
+	}
+
+	return nil
+}
+
+func (r *TagRepository) RemoveMovieTag(userID, movieId int, name string) error {
+	query := `
+		DELETE FROM public.movie_tags
+		WHERE movie_id=$1 AND tag_id IN (SELECT id FROM public.tags WHERE user_id=$3 AND lower(name) = lower($2))`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := r.DB.ExecContext(ctx, query, movieId, name, userID)
+	if err != nil {
+		return fmt.Errorf("erro ao remover tag do filme: %w", err)
+	}
+
+	return expectAffected(result)
+}
+
+func addMovieTag(ctx context.Context, tx *sql.Tx, userID, movieId int, name string) error {
+	var tagId int
+	err := tx.QueryRowContext(ctx, `
+		WITH inserted AS (
+			INSERT INTO public.tags (name, user_id) VALUES ($1, $2)
+			ON CONFLICT (user_id, lower(name)) DO NOTHING
+			RETURNING id
+		)
+		SELECT id FROM inserted
+		UNION ALL
+		SELECT id FROM public.tags WHERE user_id = $2 AND lower(name) = lower($1)
+		LIMIT 1`, name, userID).Scan(&tagId)
+	if err != nil {
+		return fmt.Errorf("erro ao salvar tag %q: %w", name, err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO public.movie_tags (movie_id, tag_id) VALUES ($1, $2)
+		ON CONFLICT DO NOTHING`, movieId, tagId)
+	if err != nil {
+		return fmt.Errorf("erro ao associar tag %q ao filme: %w", name, err)
+	}
+
+	return nil
+}
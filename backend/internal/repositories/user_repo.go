@@ -0,0 +1,273 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"letterboxd-viewer-backend/internal/models"
+	"time"
+)
+
+const userColumns = `id, username, letterboxd_username, rss_file_path, created_at`
+
+// Tabelas cujos registros pertencem a um usuário.
+var ownedTables = []string{"filmes", "watchlist", "lists", "tags"}
+
+var (
+	ErrUsernameTaken = errors.New("nome de usuário já está em uso")
+	ErrUsersExist    = errors.New("a instância já tem usuários cadastrados")
+)
+
+type UserRepository struct {
+	DB *sql.DB
+}
+
+func NewUserRepository(db *sql.DB) *UserRepository {
+	return &UserRepository{
+		DB: db,
+	}
+}
+
+// CreateUser cadastra o usuário. O primeiro cadastro da instância herda os
+// dados sem dono, criados antes de existirem contas. Com onlyFirst, o cadastro
+// só acontece se a tabela estiver vazia; caso contrário retorna ErrUsersExist.
+func (r *UserRepository) CreateUser(username, passwordHash string, onlyFirst bool) (*models.User, error) {
+	query := `
+		INSERT INTO public.users (username, password_hash)
+		VALUES ($1, $2)
+		ON CONFLICT (username) DO NOTHING
+		RETURNING ` + userColumns
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Serializa os cadastros: dois primeiros registros simultâneos veriam a
+	// tabela vazia e ambos seriam aceitos com o cadastro fechado.
+	if _, err := tx.ExecContext(ctx, `LOCK TABLE public.users IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		return nil, fmt.Errorf("erro ao bloquear tabela de usuários: %w", err)
+	}
+
+	if onlyFirst {
+		var exists bool
+		if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM public.users)`).Scan(&exists); err != nil {
+			return nil, fmt.Errorf("erro ao verificar usuários: %w", err)
+		}
+		if exists {
+			return nil, ErrUsersExist
+		}
+	}
+
+	user, err := scanUser(tx.QueryRowContext(ctx, query, username, passwordHash))
+	if err == sql.ErrNoRows {
+		return nil, ErrUsernameTaken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar usuário: %w", err)
+	}
+
+	var first bool
+	if err := tx.QueryRowContext(ctx, `SELECT min(id) = $1 FROM public.users`, user.ID).Scan(&first); err != nil {
+		return nil, fmt.Errorf("erro ao verificar usuários: %w", err)
+	}
+	if first {
+		for _, table := range ownedTables {
+			query := `UPDATE public.` + table + ` SET user_id=$1 WHERE user_id IS NULL`
+			if _, err := tx.ExecContext(ctx, query, user.ID); err != nil {
+				return nil, fmt.Errorf("erro ao transferir dados de %s: %w", table, err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("erro ao confirmar cadastro: %w", err)
+	}
+
+	return user, nil
+}
+
+// GetUserCredentials retorna sql.ErrNoRows quando o usuário não existe.
+func (r *UserRepository) GetUserCredentials(username string) (*models.User, string, error) {
+	query := `SELECT ` + userColumns + `, password_hash FROM public.users WHERE username=$1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var passwordHash string
+	user, err := scanUser(r.DB.QueryRowContext(ctx, query, username), &passwordHash)
+	if err == sql.ErrNoRows {
+		return nil, "", err
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("erro ao buscar usuário: %w", err)
+	}
+
+	return user, passwordHash, nil
+}
+
+func (r *UserRepository) GetUserByUsername(username string) (*models.User, error) {
+	query := `SELECT ` + userColumns + ` FROM public.users WHERE username=$1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	user, err := scanUser(r.DB.QueryRowContext(ctx, query, username))
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar usuário: %w", err)
+	}
+
+	return user, nil
+}
+
+// GetDefaultUser retorna o dono da instância (o primeiro usuário cadastrado),
+// cujo diário é o exibido para visitantes anônimos.
+func (r *UserRepository) GetDefaultUser() (*models.User, error) {
+	query := `SELECT ` + userColumns + ` FROM public.users ORDER BY id LIMIT 1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	user, err := scanUser(r.DB.QueryRowContext(ctx, query))
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar usuário padrão: %w", err)
+	}
+
+	return user, nil
+}
+
+func (r *UserRepository) GetUsers() ([]models.User, error) {
+	users := []models.User{}
+	query := `SELECT ` + userColumns + ` FROM public.users ORDER BY id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := r.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar usuários: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler usuário: %w", err)
+		}
+		users = append(users, *user)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre os usuários: %w", err)
+	}
+
+	return users, nil
+}
+
+// UpdateProfile grava o usuário do Letterboxd e o arquivo RSS local.
+func (r *UserRepository) UpdateProfile(user *models.User) error {
+	query := `UPDATE public.users SET letterboxd_username=$2, rss_file_path=$3 WHERE id=$1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := r.DB.ExecContext(ctx, query, user.ID, user.LetterboxdUsername, user.RSSFilePath)
+	if err != nil {
+		return fmt.Errorf("erro ao atualizar perfil: %w", err)
+	}
+
+	return expectAffected(result)
+}
+
+// CreateSession aproveita o login para descartar as sessões vencidas do usuário.
+func (r *UserRepository) CreateSession(userID int, tokenHash string, expiresAt time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM public.sessions WHERE user_id=$1 AND expires_at <= now()`, userID); err != nil {
+		return fmt.Errorf("erro ao remover sessões expiradas: %w", err)
+	}
+
+	query := `INSERT INTO public.sessions (token_hash, user_id, expires_at) VALUES ($1, $2, $3)`
+	if _, err := tx.ExecContext(ctx, query, tokenHash, userID, expiresAt); err != nil {
+		return fmt.Errorf("erro ao criar sessão: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// GetSessionUser retorna sql.ErrNoRows quando a sessão não existe ou expirou.
+func (r *UserRepository) GetSessionUser(tokenHash string) (*models.User, error) {
+	query := `
+		SELECT u.id, u.username, u.letterboxd_username, u.rss_file_path, u.created_at
+		FROM public.sessions s
+		JOIN public.users u ON u.id = s.user_id
+		WHERE s.token_hash=$1 AND s.expires_at > now()`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	user, err := scanUser(r.DB.QueryRowContext(ctx, query, tokenHash))
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar sessão: %w", err)
+	}
+
+	return user, nil
+}
+
+func (r *UserRepository) DeleteSession(tokenHash string) error {
+	query := `DELETE FROM public.sessions WHERE token_hash=$1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := r.DB.ExecContext(ctx, query, tokenHash); err != nil {
+		return fmt.Errorf("erro ao encerrar sessão: %w", err)
+	}
+
+	return nil
+}
+
+func scanUser(row rowScanner, extra ...any) (*models.User, error) {
+	var user models.User
+	dest := []any{&user.ID, &user.Username, &user.LetterboxdUsername, &user.RSSFilePath, &user.CreatedAt}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func GetUserByUsername(db *sql.DB, username string) (*models.User, error) {
+	repo := NewUserRepository(db)
+	return repo.GetUserByUsername(username)
+}
+
+func GetDefaultUser(db *sql.DB) (*models.User, error) {
+	repo := NewUserRepository(db)
+	return repo.GetDefaultUser()
+}
+
+func GetUsers(db *sql.DB) ([]models.User, error) {
+	repo := NewUserRepository(db)
+	return repo.GetUsers()
+}
@@ -0,0 +1,135 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"letterboxd-viewer-backend/internal/models"
+	"time"
+)
+
+type WatchlistRepository struct {
+	DB *sql.DB
+}
+
+func NewWatchlistRepository(db *sql.DB) *WatchlistRepository {
+	return &WatchlistRepository{
+		DB: db,
+	}
+}
+
+func (r *WatchlistRepository) GetWatchlist(userID int) ([]models.WatchlistItem, error) {
+	items := []models.WatchlistItem{}
+	query := `
+		SELECT id, tmdb_id, title, original_title, year, genre, plot, runtime, poster_path,
+			letterboxd_uri, source, to_char(added_date, 'YYYY-MM-DD')
+		FROM public.watchlist
+		WHERE user_id=$1
+		ORDER BY added_date DESC, id DESC`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar watchlist: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item models.WatchlistItem
+		err := rows.Scan(
+			&item.ID, &item.TMDBId, &item.Title, &item.OriginalTitle, &item.Year, &item.Genre, &item.Plot,
+			&item.Runtime, &item.PosterPath, &item.LetterboxdURI, &item.Source, &item.AddedDate,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler item da watchlist: %w", err)
+		}
+		items = append(items, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre a watchlist: %w", err)
+	}
+
+	return items, nil
+}
+
+// AddItem retorna false quando o filme já está na watchlist ou já foi
+// registrado no diário.
+func (r *WatchlistRepository) AddItem(userID int, item *models.WatchlistItem) (bool, error) {
+	query := `
+		INSERT INTO public.watchlist (
+			tmdb_id, title, original_title, year, genre, plot, runtime, poster_path,
+			letterboxd_uri, source, added_date, user_id
+		)
+		SELECT $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, coalesce($11::date, CURRENT_DATE), $12
+		WHERE NOT EXISTS (SELECT 1 FROM public.filmes WHERE user_id=$12 AND tmdb_id=$1)
+		ON CONFLICT (user_id, tmdb_id) DO NOTHING`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := r.DB.ExecContext(ctx, query,
+		item.TMDBId, item.Title, item.OriginalTitle, item.Year, item.Genre, item.Plot, item.Runtime,
+		item.PosterPath, item.LetterboxdURI, item.Source, toNullString(item.AddedDate), userID,
+	)
+	if err != nil {
+		return false, fmt.Errorf("erro ao adicionar filme à watchlist: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("erro ao verificar linhas afetadas: %w", err)
+	}
+
+	return affected > 0, nil
+}
+
+func (r *WatchlistRepository) RemoveItem(userID int, tmdbId string) error {
+	query := `DELETE FROM public.watchlist WHERE user_id=$1 AND tmdb_id=$2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := r.DB.ExecContext(ctx, query, userID, tmdbId)
+	if err != nil {
+		return fmt.Errorf("erro ao remover filme da watchlist: %w", err)
+	}
+
+	return expectAffected(result)
+}
+
+func (r *WatchlistRepository) ItemExists(userID int, tmdbId string) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM public.watchlist WHERE user_id=$1 AND tmdb_id=$2)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := r.DB.QueryRowContext(ctx, query, userID, tmdbId).Scan(&exists); err != nil {
+		return false, fmt.Errorf("erro ao verificar watchlist: %w", err)
+	}
+
+	return exists, nil
+}
+
+func GetWatchlist(db *sql.DB, userID int) ([]models.WatchlistItem, error) {
+	repo := NewWatchlistRepository(db)
+	return repo.GetWatchlist(userID)
+}
+
+func AddWatchlistItem(db *sql.DB, userID int, item *models.WatchlistItem) (bool, error) {
+	repo := NewWatchlistRepository(db)
+	return repo.AddItem(userID, item)
+}
+
+func RemoveWatchlistItem(db *sql.DB, userID int, tmdbId string) error {
+	repo := NewWatchlistRepository(db)
+	return repo.RemoveItem(userID, tmdbId)
+}
+
+func WatchlistItemExists(db *sql.DB, userID int, tmdbId string) (bool, error) {
+	repo := NewWatchlistRepository(db)
+	return repo.ItemExists(userID, tmdbId)
+}
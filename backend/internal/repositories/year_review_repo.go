@@ -0,0 +1,182 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"letterboxd-viewer-backend/internal/models"
+	"time"
+)
+
+const yearReviewTopLimit = 10
+
+type YearReviewRepository struct {
+	DB    *sql.DB
+	stats *StatsRepository
+}
+
+func NewYearReviewRepository(db *sql.DB) *YearReviewRepository {
+	return &YearReviewRepository{
+		DB:    db,
+		stats: NewStatsRepository(db),
+	}
+}
+
+func (r *YearReviewRepository) GetYearReview(userID, year int) (*models.YearReview, error) {
+	review := &models.YearReview{Year: year}
+	args := DateRange{
+		From: fmt.Sprintf("%04d-01-01", year),
+		To:   fmt.Sprintf("%04d-12-31", year),
+	}.args(userID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	var average sql.NullFloat64
+	err := r.DB.QueryRowContext(ctx, `
+		SELECT count(*),
+			coalesce(sum(runtime), 0) / 60.0,
+			count(*) FILTER (WHERE NOT rewatch),
+			count(*) FILTER (WHERE rewatch),
+			avg(`+memberRatingExpr+`),
+			count(*) FILTER (WHERE NOT EXISTS (
+				SELECT 1 FROM public.movie_credits_index c WHERE c.tmdb_id = filmes.tmdb_id
+			))
+		FROM public.filmes
+		WHERE `+watchedDateFilter, args...).Scan(
+		&review.FilmsWatched, &review.HoursWatched, &review.NewFilms, &review.Rewatches, &average,
+		&review.FilmsWithoutCredits,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao calcular totais do ano: %w", err)
+	}
+	review.AverageRating = nullFloatPtr(average)
+
+	review.TopRated, err = r.queryMovies(ctx, memberRatingExpr+` IS NOT NULL`,
+		memberRatingExpr+` DESC, watched_date`, yearReviewTopLimit, args)
+	if err != nil {
+		return nil, err
+	}
+
+	review.TopDirectors, err = r.stats.queryCounts(ctx, `
+		SELECT trim(d), count(*)
+		FROM public.filmes, unnest(string_to_array(nullif(director, ''), ',')) AS d
+		WHERE `+watchedDateFilter+`
+		GROUP BY trim(d)
+		ORDER BY count(*) DESC, trim(d)
+		LIMIT `+fmt.Sprint(yearReviewTopLimit), args)
+	if err != nil {
+		return nil, err
+	}
+
+	review.TopActors, err = r.stats.queryCounts(ctx, `
+		SELECT p.name, count(DISTINCT f.id)
+		FROM public.filmes f
+		JOIN public.movie_cast mc ON mc.tmdb_id = f.tmdb_id AND f.tmdb_id <> ''
+		JOIN public.people p ON p.id = mc.person_id
+		WHERE `+watchedDateFilter+`
+		GROUP BY p.id, p.name
+		ORDER BY count(DISTINCT f.id) DESC, p.name
+		LIMIT `+fmt.Sprint(yearReviewTopLimit), args)
+	if err != nil {
+		return nil, err
+	}
+
+	review.Genres, err = r.stats.queryCounts(ctx, `
+		SELECT trim(g), count(*)
+		FROM public.filmes, unnest(string_to_array(nullif(genre, ''), ',')) AS g
+		WHERE `+watchedDateFilter+`
+		GROUP BY trim(g)
+		ORDER BY count(*) DESC, trim(g)`, args)
+	if err != nil {
+		return nil, err
+	}
+
+	singles := []struct {
+		target **models.Movie
+		where  string
+		order  string
+	}{
+		{&review.Longest, `runtime > 0`, `runtime DESC, watched_date`},
+		{&review.Shortest, `runtime > 0`, `runtime, watched_date`},
+		{&review.Oldest, `release_date IS NOT NULL`, `release_date, watched_date`},
+		{&review.Newest, `release_date IS NOT NULL`, `release_date DESC, watched_date`},
+		{&review.FirstFilm, `TRUE`, `watched_date, id`},
+		{&review.LastFilm, `TRUE`, `watched_date DESC, id DESC`},
+	}
+	for _, single := range singles {
+		movies, err := r.queryMovies(ctx, single.where, single.order, 1, args)
+		if err != nil {
+			return nil, err
+		}
+		if len(movies) > 0 {
+			*single.target = &movies[0]
+		}
+	}
+
+	review.BusiestMonth, err = r.queryBusiest(ctx, `'YYYY-MM'`, args)
+	if err != nil {
+		return nil, err
+	}
+
+	review.BusiestDay, err = r.queryBusiest(ctx, `'YYYY-MM-DD'`, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return review, nil
+}
+
+func (r *YearReviewRepository) queryMovies(ctx context.Context, where, order string, limit int, args []any) ([]models.Movie, error) {
+	movies := []models.Movie{}
+	query := `SELECT ` + movieColumns + `
+		FROM public.filmes
+		WHERE ` + watchedDateFilter + ` AND ` + where + `
+		ORDER BY ` + order + `
+		LIMIT ` + fmt.Sprint(limit)
+
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar filmes do ano: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var movie models.Movie
+		if err := scanMovie(rows, &movie); err != nil {
+			return nil, fmt.Errorf("erro ao ler filme do ano: %w", err)
+		}
+		movies = append(movies, movie)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre os filmes do ano: %w", err)
+	}
+
+	return movies, nil
+}
+
+func (r *YearReviewRepository) queryBusiest(ctx context.Context, format string, args []any) (*models.CountStat, error) {
+	var busiest models.CountStat
+	err := r.DB.QueryRowContext(ctx, `
+		SELECT to_char(watched_date, `+format+`) AS period, count(*)
+		FROM public.filmes
+		WHERE `+watchedDateFilter+`
+		GROUP BY period
+		ORDER BY count(*) DESC, period
+		LIMIT 1`, args...).Scan(&busiest.Label, &busiest.Count)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("erro ao calcular período mais movimentado: %w", err)
+	}
+
+	return &busiest, nil
+}
+
+func GetYearReview(db *sql.DB, userID, year int) (*models.YearReview, error) {
+	repo := NewYearReviewRepository(db)
+	return repo.GetYearReview(userID, year)
+}
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"letterboxd-viewer-backend/internal/models"
+	"letterboxd-viewer-backend/internal/repositories"
+)
+
+const (
+	// O prefixo deixa as chaves fáceis de achar em logs e scanners de segredos.
+	apiKeyTokenPrefix     = "cdk_"
+	apiKeyDisplayLength   = 12
+	maxAPIKeyNameLength   = 64
+	maxAPIKeyRateLimit    = 6000
+	apiKeyRateLimitWindow = time.Minute
+)
+
+var (
+	ErrInvalidAPIKey          = errors.New("chave de API inválida ou revogada")
+	ErrInvalidAPIKeyName      = fmt.Errorf("nome da chave deve ter entre 1 e %d caracteres", maxAPIKeyNameLength)
+	ErrInvalidAPIKeyScope     = errors.New("scope deve ser read ou write")
+	ErrInvalidAPIKeyRateLimit = fmt.Errorf("rateLimit deve estar entre 1 e %d requisições por minuto", maxAPIKeyRateLimit)
+)
+
+// APIKeyService cuida das chaves pessoais usadas por scripts e integrações.
+// Como as sessões, só o hash da chave fica no banco; a chave em si aparece
+// uma única vez, na criação.
+type APIKeyService struct {
+	Keys             *repositories.APIKeyRepository
+	DefaultRateLimit int
+
+	limiter *rateLimiter
+}
+
+func NewAPIKeyService(db *sql.DB, defaultRateLimit int) *APIKeyService {
+	return &APIKeyService{
+		Keys:             repositories.NewAPIKeyRepository(db),
+		DefaultRateLimit: defaultRateLimit,
+		limiter:          &rateLimiter{buckets: make(map[int]*tokenBucket)},
+	}
+}
+
+// CreateKey devolve a chave gravada e o token, que não pode ser recuperado
+// depois. rateLimit zero usa o limite padrão da configuração.
+func (s *APIKeyService) CreateKey(user *models.User, name, scope string, rateLimit int) (*models.APIKey, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > maxAPIKeyNameLength {
+		return nil, "", ErrInvalidAPIKeyName
+	}
+	if scope != models.APIKeyScopeRead && scope != models.APIKeyScopeWrite {
+		return nil, "", ErrInvalidAPIKeyScope
+	}
+	if rateLimit == 0 {
+		rateLimit = s.DefaultRateLimit
+	}
+	if rateLimit < 0 || rateLimit > maxAPIKeyRateLimit {
+		return nil, "", ErrInvalidAPIKeyRateLimit
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, "", fmt.Errorf("erro ao gerar chave de API: %w", err)
+	}
+	token := apiKeyTokenPrefix + base64.RawURLEncoding.EncodeToString(raw)
+
+	key := &models.APIKey{
+		UserID:    user.ID,
+		Name:      name,
+		Prefix:    token[:apiKeyDisplayLength],
+		Scope:     scope,
+		RateLimit: rateLimit,
+	}
+	if err := s.Keys.CreateAPIKey(key, hashToken(token)); err != nil {
+		return nil, "", err
+	}
+
+	return key, token, nil
+}
+
+func (s *APIKeyService) ListKeys(user *models.User) ([]models.APIKey, error) {
+	return s.Keys.GetAPIKeys(user.ID)
+}
+
+// RevokeKey retorna sql.ErrNoRows quando a chave não é do usuário.
+func (s *APIKeyService) RevokeKey(user *models.User, id int) error {
+	if err := s.Keys.DeleteAPIKey(user.ID, id); err != nil {
+		return err
+	}
+	s.limiter.forget(id)
+	return nil
+}
+
+// Authenticate devolve a chave e o dono dela a partir do token recebido no
+// cabeçalho Authorization.
+func (s *APIKeyService) Authenticate(token string) (*models.APIKey, *models.User, error) {
+	if !strings.HasPrefix(token, apiKeyTokenPrefix) {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	key, user, err := s.Keys.GetAPIKeyUser(hashToken(token))
+	if err == sql.ErrNoRows {
+		return nil, nil, ErrInvalidAPIKey
+	}
+	return key, user, err
+}
+
+func (s *APIKeyService) Touch(key *models.APIKey) error {
+	return s.Keys.TouchAPIKey(key.ID)
+}
+
+// Allow consome uma requisição da cota da chave. Quando a cota acaba,
+// informa quanto tempo falta para a próxima requisição ser aceita.
+func (s *APIKeyService) Allow(key *models.APIKey) (ok bool, remaining int, retryAfter time.Duration) {
+	return s.limiter.allow(key.ID, key.RateLimit, time.Now())
+}
+
+// rateLimiter é um token bucket em memória por chave: a cota inteira fica
+// disponível de uma vez e é reposta aos poucos ao longo de um minuto. Com
+// mais de uma instância da API, cada uma aplica o limite separadamente.
+type rateLimiter struct {
+	mu      sync.Mutex
+	buckets map[int]*tokenBucket
+}
+
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+func (l *rateLimiter) allow(id, limit int, now time.Time) (bool, int, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	rate := float64(limit) / apiKeyRateLimitWindow.Seconds()
+	bucket, ok := l.buckets[id]
+	if !ok {
+		bucket = &tokenBucket{tokens: float64(limit), updated: now}
+		l.buckets[id] = bucket
+	}
+
+	bucket.tokens = min(float64(limit), bucket.tokens+now.Sub(bucket.updated).Seconds()*rate)
+	bucket.updated = now
+
+	if bucket.tokens < 1 {
+		wait := time.Duration((1 - bucket.tokens) / rate * float64(time.Second))
+		return false, 0, wait
+	}
+	bucket.tokens--
+	return true, int(bucket.tokens), 0
+}
+
+func (l *rateLimiter) forget(id int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.buckets, id)
+}
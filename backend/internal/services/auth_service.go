@@ -0,0 +1,171 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"letterboxd-viewer-backend/internal/models"
+	"letterboxd-viewer-backend/internal/repositories"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	minPasswordLength = 8
+	// O bcrypt ignora tudo depois do 72º byte; senhas maiores seriam truncadas em silêncio.
+	maxPasswordLength = 72
+)
+
+var (
+	ErrInvalidCredentials = errors.New("usuário ou senha inválidos")
+	ErrInvalidSession     = errors.New("sessão inválida ou expirada")
+	ErrRegistrationClosed = errors.New("cadastro de novos usuários desativado")
+	ErrInvalidUsername    = errors.New("nome de usuário deve ter de 2 a 32 letras, números ou _")
+	ErrInvalidPassword    = fmt.Errorf("senha deve ter entre %d e %d bytes", minPasswordLength, maxPasswordLength)
+
+	ErrInvalidLetterboxdUsername = errors.New("usuário do Letterboxd inválido")
+)
+
+// Mesmo formato dos nomes de usuário do Letterboxd.
+var usernamePattern = regexp.MustCompile(`^[a-z0-9_]{2,32}$`)
+
+type AuthService struct {
+	Users             *repositories.UserRepository
+	SessionTTL        time.Duration
+	AllowRegistration bool
+
+	// Comparado quando o usuário não existe, para que o tempo de resposta
+	// não revele quais nomes estão cadastrados.
+	dummyHash []byte
+}
+
+type Session struct {
+	Token     string       `json:"-"`
+	User      *models.User `json:"user"`
+	ExpiresAt time.Time    `json:"expiresAt"`
+}
+
+func NewAuthService(db *sql.DB, sessionTTL time.Duration, allowRegistration bool) *AuthService {
+	dummyHash, _ := bcrypt.GenerateFromPassword([]byte("cinedrome"), bcrypt.DefaultCost)
+	return &AuthService{
+		Users:             repositories.NewUserRepository(db),
+		SessionTTL:        sessionTTL,
+		AllowRegistration: allowRegistration,
+		dummyHash:         dummyHash,
+	}
+}
+
+// Register cria o usuário. Com o cadastro fechado, só o primeiro usuário da
+// instalação consegue se registrar.
+func (s *AuthService) Register(username, password string) (*models.User, error) {
+	username = NormalizeUsername(username)
+	if !ValidUsername(username) {
+		return nil, ErrInvalidUsername
+	}
+	if len(password) < minPasswordLength || len(password) > maxPasswordLength {
+		return nil, ErrInvalidPassword
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao gerar hash da senha: %w", err)
+	}
+
+	user, err := s.Users.CreateUser(username, string(hash), !s.AllowRegistration)
+	if errors.Is(err, repositories.ErrUsersExist) {
+		return nil, ErrRegistrationClosed
+	}
+	return user, err
+}
+
+func (s *AuthService) Login(username, password string) (*Session, error) {
+	user, hash, err := s.Users.GetUserCredentials(NormalizeUsername(username))
+	if err == sql.ErrNoRows {
+		bcrypt.CompareHashAndPassword(s.dummyHash, []byte(password))
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	return s.createSession(user)
+}
+
+// Authenticate devolve o dono da sessão. Só o hash do token fica no banco,
+// então um vazamento da tabela de sessões não permite reutilizá-las.
+func (s *AuthService) Authenticate(token string) (*models.User, error) {
+	if token == "" {
+		return nil, ErrInvalidSession
+	}
+
+	user, err := s.Users.GetSessionUser(hashToken(token))
+	if err == sql.ErrNoRows {
+		return nil, ErrInvalidSession
+	}
+	return user, err
+}
+
+func (s *AuthService) Logout(token string) error {
+	if token == "" {
+		return nil
+	}
+	return s.Users.DeleteSession(hashToken(token))
+}
+
+func (s *AuthService) createSession(user *models.User) (*Session, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, fmt.Errorf("erro ao gerar token de sessão: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	expiresAt := time.Now().Add(s.SessionTTL)
+	if err := s.Users.CreateSession(user.ID, hashToken(token), expiresAt); err != nil {
+		return nil, err
+	}
+
+	return &Session{Token: token, User: user, ExpiresAt: expiresAt}, nil
+}
+
+// UpdateLetterboxdUsername define de qual perfil do Letterboxd vem o feed do
+// usuário; um nome vazio desfaz a ligação.
+func (s *AuthService) UpdateLetterboxdUsername(user *models.User, letterboxdUsername string) error {
+	letterboxdUsername = NormalizeUsername(letterboxdUsername)
+	if letterboxdUsername != "" && !ValidUsername(letterboxdUsername) {
+		return ErrInvalidLetterboxdUsername
+	}
+
+	updated := *user
+	updated.LetterboxdUsername = letterboxdUsername
+	if err := s.Users.UpdateProfile(&updated); err != nil {
+		return err
+	}
+	*user = updated
+	return nil
+}
+
+// ValidUsername espera o nome já normalizado.
+func ValidUsername(username string) bool {
+	return usernamePattern.MatchString(username)
+}
+
+func NormalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
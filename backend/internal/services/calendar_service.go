@@ -0,0 +1,72 @@
+package services
+
+import (
+	"letterboxd-viewer-backend/internal/models"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+// BuildCalendar espera os dias ativos em ordem e dentro de [from, to]. O
+// maior intervalo sem filmes conta também os dias entre from e o primeiro
+// filme e entre o último filme e to.
+func BuildCalendar(days []models.CalendarDay, from, to time.Time) *models.Calendar {
+	calendar := &models.Calendar{
+		From:       from.Format(dateLayout),
+		To:         to.Format(dateLayout),
+		Days:       days,
+		ActiveDays: len(days),
+	}
+
+	// Primeiro dia ainda não coberto por um filme.
+	idleFrom := from
+	var previous time.Time
+	var run models.Streak
+	for _, day := range days {
+		date, err := time.Parse(dateLayout, day.Date)
+		if err != nil {
+			continue
+		}
+
+		considerGap(calendar, idleFrom, date.AddDate(0, 0, -1))
+		idleFrom = date.AddDate(0, 0, 1)
+
+		if !previous.IsZero() && daysBetween(previous, date) == 1 {
+			run.Length++
+			run.End = day.Date
+		} else {
+			run = models.Streak{Length: 1, Start: day.Date, End: day.Date}
+		}
+
+		if run.Length > calendar.LongestStreak.Length {
+			calendar.LongestStreak = run
+		}
+		previous = date
+	}
+	considerGap(calendar, idleFrom, to)
+
+	// A sequência atual continua valendo se o último filme foi visto no último
+	// dia do intervalo ou no dia anterior (o dia de hoje ainda não terminou).
+	if !previous.IsZero() && daysBetween(previous, to) <= 1 {
+		calendar.CurrentStreak = run
+	}
+
+	return calendar
+}
+
+// considerGap registra o intervalo sem filmes de start a end, inclusive, se
+// ele for o maior até agora. Intervalos vazios são ignorados.
+func considerGap(calendar *models.Calendar, start, end time.Time) {
+	length := daysBetween(start, end) + 1
+	if length > calendar.LongestGap.Length {
+		calendar.LongestGap = models.Streak{
+			Length: length,
+			Start:  start.Format(dateLayout),
+			End:    end.Format(dateLayout),
+		}
+	}
+}
+
+func daysBetween(start, end time.Time) int {
+	return int(end.Sub(start).Hours() / 24)
+}
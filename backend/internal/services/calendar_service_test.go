@@ -0,0 +1,94 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"letterboxd-viewer-backend/internal/models"
+)
+
+func TestBuildCalendar(t *testing.T) {
+	date := func(value string) time.Time {
+		parsed, err := time.Parse(dateLayout, value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return parsed
+	}
+	active := func(dates ...string) []models.CalendarDay {
+		days := make([]models.CalendarDay, 0, len(dates))
+		for _, d := range dates {
+			days = append(days, models.CalendarDay{Date: d, Count: 1})
+		}
+		return days
+	}
+
+	tests := []struct {
+		name    string
+		days    []models.CalendarDay
+		from    string
+		to      string
+		gap     models.Streak
+		longest models.Streak
+		current models.Streak
+	}{
+		{
+			name: "intervalo vazio",
+			from: "2024-01-01",
+			to:   "2024-01-31",
+			gap:  models.Streak{Length: 31, Start: "2024-01-01", End: "2024-01-31"},
+		},
+		{
+			name:    "maior intervalo antes do primeiro filme",
+			days:    active("2024-01-20", "2024-01-22"),
+			from:    "2024-01-01",
+			to:      "2024-01-22",
+			gap:     models.Streak{Length: 19, Start: "2024-01-01", End: "2024-01-19"},
+			longest: models.Streak{Length: 1, Start: "2024-01-20", End: "2024-01-20"},
+			current: models.Streak{Length: 1, Start: "2024-01-22", End: "2024-01-22"},
+		},
+		{
+			name:    "maior intervalo depois do último filme",
+			days:    active("2024-01-01", "2024-01-02", "2024-01-04"),
+			from:    "2024-01-01",
+			to:      "2024-01-31",
+			gap:     models.Streak{Length: 27, Start: "2024-01-05", End: "2024-01-31"},
+			longest: models.Streak{Length: 2, Start: "2024-01-01", End: "2024-01-02"},
+		},
+		{
+			name:    "maior intervalo entre dois filmes",
+			days:    active("2024-01-01", "2024-01-10", "2024-01-11"),
+			from:    "2024-01-01",
+			to:      "2024-01-12",
+			gap:     models.Streak{Length: 8, Start: "2024-01-02", End: "2024-01-09"},
+			longest: models.Streak{Length: 2, Start: "2024-01-10", End: "2024-01-11"},
+			current: models.Streak{Length: 2, Start: "2024-01-10", End: "2024-01-11"},
+		},
+		{
+			name:    "todos os dias com filmes",
+			days:    active("2024-01-01", "2024-01-02", "2024-01-03"),
+			from:    "2024-01-01",
+			to:      "2024-01-03",
+			longest: models.Streak{Length: 3, Start: "2024-01-01", End: "2024-01-03"},
+			current: models.Streak{Length: 3, Start: "2024-01-01", End: "2024-01-03"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calendar := BuildCalendar(tt.days, date(tt.from), date(tt.to))
+			if calendar.LongestGap != tt.gap {
+				t.Errorf("LongestGap = %+v, esperado %+v", calendar.LongestGap, tt.gap)
+			}
+			if calendar.LongestStreak != tt.longest {
+				t.Errorf("LongestStreak = %+v, esperado %+v", calendar.LongestStreak, tt.longest)
+			}
+			if calendar.CurrentStreak != tt.current {
+				t.Errorf("CurrentStreak = %+v, esperado %+v", calendar.CurrentStreak, tt.current)
+			}
+			if calendar.ActiveDays != len(tt.days) {
+				t.Errorf("ActiveDays = %d, esperado %d", calendar.ActiveDays, len(tt.days))
+			}
+		})
+	}
+}
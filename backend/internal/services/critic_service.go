@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"strings"
+	"unicode/utf8"
+
+	"letterboxd-viewer-backend/internal/models"
+	"letterboxd-viewer-backend/internal/repositories"
+)
+
+const reviewSnippetLength = 280
+
+var ErrInvalidCritic = errors.New("usuário do Letterboxd inválido")
+
+// CriticService acompanha os feeds de outros membros do Letterboxd. As
+// entradas deles ficam em uma tabela própria, separadas dos diários dos
+// usuários, e são lidas com o mesmo parser do feed do diário.
+type CriticService struct {
+	Critics     *repositories.CriticRepository
+	SyncService *SyncService
+	Similarity  *SimilarityService
+	Logger      *log.Logger
+}
+
+func NewCriticService(db *sql.DB, syncService *SyncService, logger *log.Logger) *CriticService {
+	return &CriticService{
+		Critics:     repositories.NewCriticRepository(db),
+		SyncService: syncService,
+		Similarity:  syncService.Similarity,
+		Logger:      logger,
+	}
+}
+
+// Follow devolve o nome normalizado do crítico; a primeira importação do
+// feed fica a cargo de quem chamou. Se outro usuário já acompanhava o
+// crítico, as entradas dele já servem para calcular a similaridade.
+func (s *CriticService) Follow(user *models.User, username string) (string, error) {
+	username = NormalizeUsername(username)
+	if !ValidUsername(username) {
+		return "", ErrInvalidCritic
+	}
+	if err := s.Critics.FollowCritic(user.ID, username); err != nil {
+		return "", err
+	}
+
+	if err := s.Similarity.Recompute(user.ID, username); err != nil {
+		s.Logger.Printf("Erro ao calcular similaridade com %s: %v", username, err)
+	}
+	return username, nil
+}
+
+func (s *CriticService) Unfollow(user *models.User, username string) error {
+	return s.Critics.UnfollowCritic(user.ID, NormalizeUsername(username))
+}
+
+// SyncCritic importa o feed público do crítico. Entradas já conhecidas têm
+// nota e resenha atualizadas e contam como ignoradas.
+func (s *CriticService) SyncCritic(ctx context.Context, username string) (*SyncResult, error) {
+	feed, err := s.SyncService.parseFeed(ctx, LetterboxdFeedURL(username))
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SyncResult{Failed: []string{}}
+	changed := false
+	for _, item := range feed.Items {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		movie := s.SyncService.MovieFromFeedItem(item)
+		// O feed também traz listas publicadas, que não são filmes.
+		if movie.Title == "" {
+			continue
+		}
+
+		entry := &models.CriticEntry{
+			Critic:       username,
+			GUID:         movie.GUID,
+			TMDBId:       movie.TMDBId,
+			Title:        movie.Title,
+			Year:         movie.Year,
+			WatchedDate:  movie.WatchedDate,
+			MemberRating: movie.MemberRating,
+			Review:       movie.ReviewText(),
+			Rewatch:      movie.Rewatch,
+			Link:         item.Link,
+		}
+		inserted, updated, err := s.Critics.SaveCriticEntry(entry)
+		if err != nil {
+			s.Logger.Printf("Erro ao salvar entrada de %s: %v", username, err)
+			result.Failed = append(result.Failed, movie.Title)
+			continue
+		}
+		changed = changed || updated
+		if entry.TMDBId != "" {
+			s.fetchMetadata(ctx, entry.TMDBId)
+		}
+		if inserted {
+			result.Inserted++
+		} else {
+			result.Skipped++
+		}
+	}
+
+	displayName := strings.TrimPrefix(feed.Title, "Letterboxd - ")
+	if err := s.Critics.MarkCriticSynced(username, displayName); err != nil {
+		return result, err
+	}
+
+	if changed {
+		if err := s.Similarity.RecomputeForCritic(username); err != nil {
+			s.Logger.Printf("Erro ao recalcular similaridade com %s: %v", username, err)
+		}
+	}
+
+	s.Logger.Printf("Feed de %s sincronizado: %d novas entradas, %d já existentes, %d falhas",
+		username, result.Inserted, result.Skipped, len(result.Failed))
+	return result, nil
+}
+
+// fetchMetadata guarda gênero, duração e pôster dos filmes que nenhum diário
+// registrou, para as recomendações não consultarem o TMDb na leitura. Uma
+// falha fica para a próxima sincronização.
+func (s *CriticService) fetchMetadata(ctx context.Context, tmdbId string) {
+	known, err := s.Critics.MovieMetadataKnown(tmdbId)
+	if err != nil {
+		s.Logger.Printf("Erro ao verificar metadados do filme %s: %v", tmdbId, err)
+		return
+	}
+	if known {
+		return
+	}
+
+	movie, err := s.SyncService.TMDBService.GetMovieInfo(ctx, tmdbId)
+	if err != nil {
+		s.Logger.Printf("Erro ao buscar metadados do filme %s: %v", tmdbId, err)
+		return
+	}
+	if err := s.Critics.SaveMovieMetadata(tmdbId, movie); err != nil {
+		s.Logger.Printf("Erro ao salvar metadados do filme %s: %v", tmdbId, err)
+	}
+}
+
+// SyncFollowed sincroniza os críticos acompanhados pelo usuário ou, com
+// user nil, por qualquer usuário. A falha em um feed não interrompe os demais.
+func (s *CriticService) SyncFollowed(ctx context.Context, user *models.User) (map[string]*SyncResult, error) {
+	userID := 0
+	if user != nil {
+		userID = user.ID
+	}
+
+	usernames, err := s.Critics.GetCriticUsernames(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(map[string]*SyncResult, len(usernames))
+	for _, username := range usernames {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+
+		result, err := s.SyncCritic(ctx, username)
+		if err != nil {
+			s.Logger.Printf("Erro ao sincronizar feed de %s: %v", username, err)
+			result = &SyncResult{Failed: []string{err.Error()}}
+		}
+		results[username] = result
+	}
+
+	return results, nil
+}
+
+// GetOpinions junta a entrada do diário com as dos críticos que o dono do
+// diário acompanha.
+func (s *CriticService) GetOpinions(movie *models.Movie) (*models.MovieOpinions, error) {
+	opinions := &models.MovieOpinions{
+		TMDBId: movie.TMDBId,
+		Mine: &models.Opinion{
+			MemberRating:  movie.MemberRating,
+			WatchedDate:   movie.WatchedDate,
+			Rewatch:       movie.Rewatch,
+			ReviewSnippet: ReviewSnippet(movie.ReviewText()),
+		},
+		Critics: []models.Opinion{},
+	}
+	if movie.TMDBId == "" {
+		return opinions, nil
+	}
+
+	critics, err := s.Critics.GetCriticOpinions(movie.UserID, movie.TMDBId)
+	if err != nil {
+		return nil, err
+	}
+	for i := range critics {
+		critics[i].ReviewSnippet = ReviewSnippet(critics[i].ReviewSnippet)
+	}
+	opinions.Critics = critics
+
+	return opinions, nil
+}
+
+// ReviewSnippet encurta a resenha no último espaço antes do limite.
+func ReviewSnippet(review string) string {
+	review = strings.Join(strings.Fields(review), " ")
+	if utf8.RuneCountInString(review) <= reviewSnippetLength {
+		return review
+	}
+
+	cut := string([]rune(review)[:reviewSnippetLength])
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, ".,;:!? ") + "…"
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"letterboxd-viewer-backend/internal/models"
+	"letterboxd-viewer-backend/internal/repositories"
+)
+
+const exportFlushEvery = 100
+
+type ExportFormat struct {
+	ContentType string
+	Extension   string
+	write       func(ctx context.Context, db *sql.DB, userID int, w *bufio.Writer, flush func()) error
+}
+
+var (
+	exportCSVHeader = []string{
+		"guid", "source", "title", "original_title", "year", "watched_date", "member_rating", "rewatch",
+		"review", "tmdb_id", "imdb_rating", "genre", "director", "runtime", "release_date",
+		"original_language", "production_countries", "production_companies", "spoken_languages",
+		"tagline", "plot", "budget", "revenue", "status", "poster_path", "backdrop_path", "homepage",
+	}
+
+	// Colunas aceitas pelo importador de CSV do Letterboxd.
+	letterboxdCSVHeader = []string{"tmdbID", "Title", "Year", "Rating", "WatchedDate", "Rewatch", "Review"}
+
+	ExportFormats = map[string]ExportFormat{
+		"csv":        {ContentType: "text/csv; charset=utf-8", Extension: "csv", write: writeCSV(exportCSVHeader, exportCSVRecord)},
+		"letterboxd": {ContentType: "text/csv; charset=utf-8", Extension: "csv", write: writeCSV(letterboxdCSVHeader, letterboxdCSVRecord)},
+		"json":       {ContentType: "application/json; charset=utf-8", Extension: "json", write: writeJSON},
+	}
+)
+
+// WriteExport grava o diário inteiro do usuário no formato pedido, lendo os
+// filmes em streaming. flush é chamado periodicamente para que respostas HTTP
+// longas comecem a chegar ao cliente antes do fim da exportação.
+func WriteExport(ctx context.Context, db *sql.DB, userID int, w io.Writer, format string, flush func()) error {
+	exportFormat, ok := ExportFormats[format]
+	if !ok {
+		return fmt.Errorf("formato de exportação desconhecido: %s", format)
+	}
+
+	out := bufio.NewWriter(w)
+	flushAll := func() {
+		out.Flush()
+		if flush != nil {
+			flush()
+		}
+	}
+
+	if err := exportFormat.write(ctx, db, userID, out, flushAll); err != nil {
+		return err
+	}
+	flushAll()
+	return nil
+}
+
+func writeCSV(header []string, record func(*models.Movie) []string) func(context.Context, *sql.DB, int, *bufio.Writer, func()) error {
+	return func(ctx context.Context, db *sql.DB, userID int, w *bufio.Writer, flush func()) error {
+		writer := csv.NewWriter(w)
+		if err := writer.Write(header); err != nil {
+			return err
+		}
+
+		count := 0
+		err := repositories.StreamMovies(ctx, db, userID, func(movie *models.Movie) error {
+			if err := writer.Write(record(movie)); err != nil {
+				return err
+			}
+			count++
+			if count%exportFlushEvery == 0 {
+				writer.Flush()
+				flush()
+			}
+			return writer.Error()
+		})
+		if err != nil {
+			return err
+		}
+
+		writer.Flush()
+		return writer.Error()
+	}
+}
+
+func writeJSON(ctx context.Context, db *sql.DB, userID int, w *bufio.Writer, flush func()) error {
+	if _, err := w.WriteString("["); err != nil {
+		return err
+	}
+
+	count := 0
+	err := repositories.StreamMovies(ctx, db, userID, func(movie *models.Movie) error {
+		data, err := json.Marshal(movie)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			if _, err := w.WriteString(","); err != nil {
+				return err
+			}
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+		count++
+		if count%exportFlushEvery == 0 {
+			flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = w.WriteString("]")
+	return err
+}
+
+func exportCSVRecord(movie *models.Movie) []string {
+	return []string{
+		movie.GUID, movie.Source, movie.Title, movie.OriginalTitle, movie.Year, movie.WatchedDate,
+		movie.MemberRating, strconv.FormatBool(movie.Rewatch), movie.ReviewText(), movie.TMDBId,
+		movie.IMDBRating, movie.Genre, movie.Director, strconv.Itoa(movie.Runtime), movie.ReleaseDate,
+		movie.OriginalLanguage, movie.ProductionCountries, movie.ProductionCompanies, movie.SpokenLanguages,
+		movie.Tagline, movie.Plot, strconv.Itoa(movie.Budget), strconv.Itoa(movie.Revenue), movie.Status,
+		movie.PosterPath, movie.BackdropPath, movie.Homepage,
+	}
+}
+
+func letterboxdCSVRecord(movie *models.Movie) []string {
+	rewatch := ""
+	if movie.Rewatch {
+		rewatch = "Yes"
+	}
+	return []string{
+		movie.TMDBId, movie.Title, movie.Year, movie.MemberRating, movie.WatchedDate, rewatch, movie.ReviewText(),
+	}
+}
@@ -0,0 +1,293 @@
+package services
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"letterboxd-viewer-backend/internal/models"
+	"strings"
+	"time"
+)
+
+const (
+	feedTitle       = "Cinedrome — Diário de filmes"
+	feedDescription = "Filmes assistidos, com notas e resenhas, enriquecidos com dados do TMDb"
+)
+
+type FeedService struct {
+	BaseURL string
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Atom    string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Language      string    `xml:"language"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	AtomLink      atomLink  `xml:"atom:link"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link,omitempty"`
+	GUID        rssGUID       `xml:"guid"`
+	PubDate     string        `xml:"pubDate"`
+	Description string        `xml:"description"`
+	Enclosure   *rssEnclosure `xml:"enclosure,omitempty"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Type   string `xml:"type,attr"`
+	Length int    `xml:"length,attr"`
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	Title     string      `xml:"title"`
+	ID        string      `xml:"id"`
+	Updated   string      `xml:"updated"`
+	Published string      `xml:"published"`
+	Links     []atomLink  `xml:"link"`
+	Content   atomContent `xml:"content"`
+}
+
+type atomContent struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type jsonFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	FeedURL     string         `json:"feed_url"`
+	Description string         `json:"description"`
+	Language    string         `json:"language"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID            string             `json:"id"`
+	URL           string             `json:"url,omitempty"`
+	Title         string             `json:"title"`
+	ContentHTML   string             `json:"content_html"`
+	ContentText   string             `json:"content_text,omitempty"`
+	Image         string             `json:"image,omitempty"`
+	DatePublished string             `json:"date_published"`
+	DateModified  string             `json:"date_modified"`
+	Tags          []string           `json:"tags,omitempty"`
+	Extension     *jsonFeedExtension `json:"_cinedrome,omitempty"`
+}
+
+type jsonFeedExtension struct {
+	TMDBId       string `json:"tmdb_id,omitempty"`
+	Year         string `json:"year,omitempty"`
+	MemberRating string `json:"member_rating,omitempty"`
+	WatchedDate  string `json:"watched_date,omitempty"`
+	Rewatch      bool   `json:"rewatch"`
+}
+
+func NewFeedService(baseURL string) *FeedService {
+	return &FeedService{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+	}
+}
+
+func (s *FeedService) BuildRSS(movies []models.Movie, updated time.Time) ([]byte, error) {
+	feed := rssFeed{
+		Version: "2.0",
+		Atom:    "http://www.w3.org/2005/Atom",
+		Channel: rssChannel{
+			Title:         feedTitle,
+			Link:          s.BaseURL,
+			Description:   feedDescription,
+			Language:      "pt-BR",
+			LastBuildDate: updated.UTC().Format(time.RFC1123Z),
+			AtomLink:      atomLink{Href: s.BaseURL + "/feeds/diary.rss", Rel: "self", Type: "application/rss+xml"},
+		},
+	}
+
+	for i := range movies {
+		movie := &movies[i]
+		item := rssItem{
+			Title:       entryTitle(movie),
+			Link:        TMDBMovieURL(movie.TMDBId),
+			GUID:        rssGUID{Value: movie.GUID},
+			PubDate:     entryPublished(movie).Format(time.RFC1123Z),
+			Description: entryHTML(movie),
+		}
+		if poster := movie.FullPosterURL(); poster != "" {
+			item.Enclosure = &rssEnclosure{URL: poster, Type: "image/jpeg"}
+		}
+		feed.Channel.Items = append(feed.Channel.Items, item)
+	}
+
+	return marshalXML(feed)
+}
+
+func (s *FeedService) BuildAtom(movies []models.Movie, updated time.Time) ([]byte, error) {
+	feed := atomFeed{
+		Title:   feedTitle,
+		ID:      s.BaseURL + "/feeds/diary.atom",
+		Updated: updated.UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: s.BaseURL + "/feeds/diary.atom", Rel: "self", Type: "application/atom+xml"},
+			{Href: s.BaseURL, Rel: "alternate"},
+		},
+	}
+
+	for i := range movies {
+		movie := &movies[i]
+		entry := atomEntry{
+			Title:     entryTitle(movie),
+			ID:        "urn:cinedrome:" + movie.GUID,
+			Updated:   movie.UpdatedAt.UTC().Format(time.RFC3339),
+			Published: entryPublished(movie).Format(time.RFC3339),
+			Content:   atomContent{Type: "html", Value: entryHTML(movie)},
+		}
+		if link := TMDBMovieURL(movie.TMDBId); link != "" {
+			entry.Links = append(entry.Links, atomLink{Href: link, Rel: "alternate"})
+		}
+		if poster := movie.FullPosterURL(); poster != "" {
+			entry.Links = append(entry.Links, atomLink{Href: poster, Rel: "enclosure", Type: "image/jpeg"})
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	return marshalXML(feed)
+}
+
+func (s *FeedService) BuildJSONFeed(movies []models.Movie) ([]byte, error) {
+	feed := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       feedTitle,
+		HomePageURL: s.BaseURL,
+		FeedURL:     s.BaseURL + "/feeds/diary.json",
+		Description: feedDescription,
+		Language:    "pt-BR",
+		Items:       []jsonFeedItem{},
+	}
+
+	for i := range movies {
+		movie := &movies[i]
+		item := jsonFeedItem{
+			ID:            movie.GUID,
+			URL:           TMDBMovieURL(movie.TMDBId),
+			Title:         entryTitle(movie),
+			ContentHTML:   entryHTML(movie),
+			ContentText:   movie.ReviewText(),
+			Image:         movie.FullPosterURL(),
+			DatePublished: entryPublished(movie).Format(time.RFC3339),
+			DateModified:  movie.UpdatedAt.UTC().Format(time.RFC3339),
+			Extension: &jsonFeedExtension{
+				TMDBId:       movie.TMDBId,
+				Year:         movie.Year,
+				MemberRating: movie.MemberRating,
+				WatchedDate:  movie.WatchedDate,
+				Rewatch:      movie.Rewatch,
+			},
+		}
+		for _, genre := range strings.Split(movie.Genre, ",") {
+			if genre = strings.TrimSpace(genre); genre != "" {
+				item.Tags = append(item.Tags, genre)
+			}
+		}
+		feed.Items = append(feed.Items, item)
+	}
+
+	return json.MarshalIndent(feed, "", "  ")
+}
+
+func TMDBMovieURL(tmdbId string) string {
+	if tmdbId == "" {
+		return ""
+	}
+	return "https://www.themoviedb.org/movie/" + tmdbId
+}
+
+func RatingStars(rating string) string {
+	var value float64
+	if _, err := fmt.Sscanf(rating, "%f", &value); err != nil || value <= 0 {
+		return ""
+	}
+
+	stars := strings.Repeat("★", int(value))
+	if value-float64(int(value)) >= 0.5 {
+		stars += "½"
+	}
+	return stars
+}
+
+func entryTitle(movie *models.Movie) string {
+	title := movie.Title
+	if movie.Year != "" {
+		title += ", " + movie.Year
+	}
+	if stars := RatingStars(movie.MemberRating); stars != "" {
+		title += " - " + stars
+	}
+	return title
+}
+
+func entryPublished(movie *models.Movie) time.Time {
+	if watched, err := movie.ParsedWatchedDate(); err == nil && watched != nil {
+		return watched.UTC()
+	}
+	return movie.UpdatedAt.UTC()
+}
+
+func entryHTML(movie *models.Movie) string {
+	var b strings.Builder
+	if poster := movie.FullPosterURL(); poster != "" {
+		fmt.Fprintf(&b, `<p><img src="%s" alt="%s"/></p>`, html.EscapeString(poster), html.EscapeString(movie.Title))
+	}
+	if stars := RatingStars(movie.MemberRating); stars != "" {
+		fmt.Fprintf(&b, "<p>Nota: %s</p>", stars)
+	}
+	for _, paragraph := range strings.Split(movie.ReviewText(), "\n\n") {
+		if paragraph != "" {
+			fmt.Fprintf(&b, "<p>%s</p>", html.EscapeString(paragraph))
+		}
+	}
+	if link := TMDBMovieURL(movie.TMDBId); link != "" {
+		fmt.Fprintf(&b, `<p><a href="%s">Ver no TMDb</a></p>`, link)
+	}
+	return b.String()
+}
+
+func marshalXML(v any) ([]byte, error) {
+	data, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao gerar XML do feed: %w", err)
+	}
+	return append([]byte(xml.Header), data...), nil
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"fmt"
+	"letterboxd-viewer-backend/internal/models"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	icalDateFormat     = "20060102"
+	icalDateTimeFormat = "20060102T150405Z"
+	icalMaxLineOctets  = 75
+)
+
+var icalEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// BuildICS gera um calendário iCalendar (RFC 5545) com um evento de dia
+// inteiro por filme assistido. O UID deriva do GUID, então reimportar o
+// calendário atualiza os eventos em vez de duplicá-los.
+func (s *FeedService) BuildICS(movies []models.Movie, updated time.Time) []byte {
+	var b strings.Builder
+	writeICalLine(&b, "BEGIN:VCALENDAR")
+	writeICalLine(&b, "VERSION:2.0")
+	writeICalLine(&b, "PRODID:-//Cinedrome//Diario de filmes//PT-BR")
+	writeICalLine(&b, "CALSCALE:GREGORIAN")
+	writeICalLine(&b, "METHOD:PUBLISH")
+	writeICalLine(&b, "X-WR-CALNAME:"+icalEscaper.Replace(feedTitle))
+	writeICalLine(&b, "X-WR-CALDESC:"+icalEscaper.Replace(feedDescription))
+
+	stamp := updated.UTC().Format(icalDateTimeFormat)
+	for i := range movies {
+		movie := &movies[i]
+		watched, err := movie.ParsedWatchedDate()
+		if err != nil || watched == nil {
+			continue
+		}
+
+		writeICalLine(&b, "BEGIN:VEVENT")
+		writeICalLine(&b, "UID:"+icalEscaper.Replace(movie.GUID)+"@cinedrome")
+		writeICalLine(&b, "DTSTAMP:"+stamp)
+		if !movie.UpdatedAt.IsZero() {
+			writeICalLine(&b, "LAST-MODIFIED:"+movie.UpdatedAt.UTC().Format(icalDateTimeFormat))
+		}
+		writeICalLine(&b, "DTSTART;VALUE=DATE:"+watched.Format(icalDateFormat))
+		writeICalLine(&b, "DTEND;VALUE=DATE:"+watched.AddDate(0, 0, 1).Format(icalDateFormat))
+		writeICalLine(&b, "SUMMARY:"+icalEscaper.Replace(entryTitle(movie)))
+		writeICalLine(&b, "DESCRIPTION:"+icalEscaper.Replace(eventDescription(movie)))
+		if link := TMDBMovieURL(movie.TMDBId); link != "" {
+			writeICalLine(&b, "URL:"+link)
+		}
+		writeICalLine(&b, "TRANSP:TRANSPARENT")
+		writeICalLine(&b, "END:VEVENT")
+	}
+
+	writeICalLine(&b, "END:VCALENDAR")
+	return []byte(b.String())
+}
+
+func eventDescription(movie *models.Movie) string {
+	var lines []string
+	title := movie.Title
+	if movie.Year != "" {
+		title = fmt.Sprintf("%s (%s)", title, movie.Year)
+	}
+	lines = append(lines, title)
+	if stars := RatingStars(movie.MemberRating); stars != "" {
+		lines = append(lines, "Nota: "+stars)
+	}
+	if movie.Rewatch {
+		lines = append(lines, "Revisto")
+	}
+	if link := TMDBMovieURL(movie.TMDBId); link != "" {
+		lines = append(lines, link)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Quebra linhas com mais de 75 octetos sem partir caracteres UTF-8,
+// continuando cada trecho com um espaço, como pede a RFC 5545.
+func writeICalLine(b *strings.Builder, line string) {
+	limit := icalMaxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		limit = icalMaxLineOctets - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha1"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"path"
+	"strings"
+
+	"letterboxd-viewer-backend/internal/models"
+	"letterboxd-viewer-backend/internal/repositories"
+)
+
+const letterboxdImportGUIDPrefix = "letterboxd-import-"
+
+// ImportLetterboxdExport importa para o diário do usuário o diary.csv do zip
+// exportado em letterboxd.com/settings/data, juntando as resenhas do
+// reviews.csv e as tags. Entradas já presentes no diário (pelo feed RSS ou
+// por importação anterior) são ignoradas.
+func (s *SyncService) ImportLetterboxdExport(ctx context.Context, userID int, zipPath string) (*SyncResult, error) {
+	archive, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir export do Letterboxd: %w", err)
+	}
+	defer archive.Close()
+
+	diary, err := readZipCSV(&archive.Reader, "diary.csv")
+	if err != nil {
+		return nil, err
+	}
+
+	reviews := map[string]string{}
+	if rows, err := readZipCSV(&archive.Reader, "reviews.csv"); err == nil {
+		for _, row := range rows {
+			reviews[row["Letterboxd URI"]] = row["Review"]
+		}
+	}
+
+	tagRepo := repositories.NewTagRepository(s.DB)
+	result := &SyncResult{Failed: []string{}}
+
+	for _, row := range diary {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		movie := &models.Movie{
+			Title:        row["Name"],
+			Year:         row["Year"],
+			WatchedDate:  row["Watched Date"],
+			MemberRating: row["Rating"],
+			Rewatch:      row["Rewatch"] == "Yes",
+			Description:  ReviewHTML(reviews[row["Letterboxd URI"]]),
+			GUID:         letterboxdImportGUID(row),
+			Source:       models.SourceLetterboxd,
+			UserID:       userID,
+		}
+
+		exists, err := repositories.CheckMovieExists(s.DB, userID, movie.GUID)
+		if err != nil {
+			s.Logger.Printf("Erro ao verificar filme no banco de dados: %v", err)
+			result.Failed = append(result.Failed, movie.Title)
+			continue
+		}
+		if exists {
+			result.Skipped++
+			continue
+		}
+
+		tmdbId, err := s.TMDBService.SearchMovieID(ctx, movie.Title, movie.Year)
+		if err != nil {
+			s.Logger.Printf("Erro ao identificar %q (%s) no TMDb: %v", movie.Title, movie.Year, err)
+			result.Failed = append(result.Failed, movie.Title)
+			continue
+		}
+		movie.TMDBId = tmdbId
+
+		watched, err := repositories.MovieWatchedOn(s.DB, userID, movie.TMDBId, movie.WatchedDate)
+		if err != nil {
+			s.Logger.Printf("Erro ao verificar filme no banco de dados: %v", err)
+			result.Failed = append(result.Failed, movie.Title)
+			continue
+		}
+		if watched {
+			result.Skipped++
+			continue
+		}
+
+		s.EnrichMovie(ctx, movie)
+		if err := repositories.InsertMovie(s.DB, movie); err != nil {
+			s.Logger.Printf("Erro ao inserir filme no banco de dados: %v", err)
+			result.Failed = append(result.Failed, movie.Title)
+			continue
+		}
+		result.Inserted++
+
+		if tags := splitTags(row["Tags"]); len(tags) > 0 {
+			saved, err := repositories.GetMovieByGUID(s.DB, userID, movie.GUID)
+			if err == nil {
+				err = tagRepo.SetMovieTags(userID, saved.ID, tags)
+			}
+			if err != nil {
+				s.Logger.Printf("Erro ao salvar tags de %s: %v", movie.Title, err)
+			}
+		}
+	}
+
+	if result.Inserted > 0 {
+		s.updateSimilarity(userID)
+	}
+
+	s.Logger.Printf("Export do Letterboxd importado: %d inseridos, %d ignorados, %d falhas",
+		result.Inserted, result.Skipped, len(result.Failed))
+	return result, nil
+}
+
+// ReviewHTML converte uma resenha em texto puro para o HTML usado no diário,
+// com um parágrafo por bloco separado por linha em branco.
+func ReviewHTML(review string) string {
+	var b strings.Builder
+	for _, paragraph := range strings.Split(strings.ReplaceAll(review, "\r\n", "\n"), "\n\n") {
+		if paragraph = strings.TrimSpace(paragraph); paragraph != "" {
+			b.WriteString("<p>" + html.EscapeString(paragraph) + "</p>")
+		}
+	}
+	return b.String()
+}
+
+// O link boxd.it da entrada é único por registro; sem ele, usamos um hash
+// de nome, ano e data para que reimportar o mesmo arquivo não duplique nada.
+func letterboxdImportGUID(row map[string]string) string {
+	if uri := strings.TrimRight(row["Letterboxd URI"], "/"); uri != "" {
+		return letterboxdImportGUIDPrefix + path.Base(uri)
+	}
+	sum := sha1.Sum([]byte(row["Name"] + "|" + row["Year"] + "|" + row["Watched Date"]))
+	return letterboxdImportGUIDPrefix + hex.EncodeToString(sum[:])[:16]
+}
+
+func splitTags(value string) []string {
+	var tags []string
+	for _, tag := range strings.Split(value, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func readZipCSV(archive *zip.Reader, name string) ([]map[string]string, error) {
+	file, err := archive.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("%s ausente no export do Letterboxd: %w", name, err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler %s: %w", name, err)
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(strings.TrimPrefix(header[i], "\ufeff"))
+	}
+
+	var rows []map[string]string
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler %s: %w", name, err)
+		}
+
+		row := make(map[string]string, len(header))
+		for i, column := range header {
+			if i < len(record) {
+				row[column] = strings.TrimSpace(record[i])
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"letterboxd-viewer-backend/internal/models"
+	"letterboxd-viewer-backend/internal/repositories"
+)
+
+const (
+	// Só recomendamos filmes que ao menos um crítico parecido avaliou bem.
+	highRating = 3.5
+	// A nota prevista parte de uma nota neutra com esse peso, para que um
+	// único crítico pouco parecido não empurre um filme ao topo.
+	neutralRating       = 3.0
+	neutralRatingWeight = 0.5
+	maxExplanations     = 3
+)
+
+// RecommendationFilter restringe as recomendações; valores zerados não filtram.
+type RecommendationFilter struct {
+	Genre      string
+	Decade     int
+	MinRuntime int
+	MaxRuntime int
+	Limit      int
+}
+
+func (f RecommendationFilter) needsMetadata() bool {
+	return f.Genre != "" || f.MinRuntime > 0 || f.MaxRuntime > 0
+}
+
+// RecommendationService sugere filmes a partir das notas dos críticos
+// acompanhados, ponderadas pela similaridade de cada um com o usuário.
+type RecommendationService struct {
+	DB              *sql.DB
+	Recommendations *repositories.RecommendationRepository
+	TMDBService     *TMDBService
+	Logger          *log.Logger
+}
+
+func NewRecommendationService(db *sql.DB, tmdbService *TMDBService, logger *log.Logger) *RecommendationService {
+	return &RecommendationService{
+		DB:              db,
+		Recommendations: repositories.NewRecommendationRepository(db),
+		TMDBService:     tmdbService,
+		Logger:          logger,
+	}
+}
+
+// Recommend só lê o banco: os metadados dos filmes vêm dos diários ou da
+// sincronização dos críticos, nunca do TMDb durante a requisição.
+func (s *RecommendationService) Recommend(userID int, filter RecommendationFilter) ([]models.Recommendation, error) {
+	candidates, err := s.Recommendations.GetCandidates(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	weights := make(map[string]float64, len(candidates))
+	scored := candidates[:0]
+	for _, movie := range candidates {
+		if !hasHighRating(movie.Ratings) {
+			continue
+		}
+		movie.PredictedRating, weights[movie.TMDBId] = PredictRating(movie.Ratings)
+		scored = append(scored, movie)
+	}
+
+	sort.SliceStable(scored, func(i, j int) bool {
+		if scored[i].PredictedRating != scored[j].PredictedRating {
+			return scored[i].PredictedRating > scored[j].PredictedRating
+		}
+		return weights[scored[i].TMDBId] > weights[scored[j].TMDBId]
+	})
+
+	recommendations := []models.Recommendation{}
+	for _, movie := range scored {
+		if len(recommendations) >= filter.Limit {
+			break
+		}
+		if filter.Decade > 0 && !inDecade(movie.Year, filter.Decade) {
+			continue
+		}
+
+		if movie.Genre == "" && filter.needsMetadata() {
+			continue
+		}
+		if filter.Genre != "" && !hasGenre(movie.Genre, filter.Genre) {
+			continue
+		}
+		if filter.MinRuntime > 0 && movie.Runtime < filter.MinRuntime {
+			continue
+		}
+		if filter.MaxRuntime > 0 && (movie.Runtime == 0 || movie.Runtime > filter.MaxRuntime) {
+			continue
+		}
+
+		movie.PredictedRating = math.Round(movie.PredictedRating*100) / 100
+		movie.Explanations = explainRecommendation(movie.Ratings)
+		recommendations = append(recommendations, movie)
+	}
+
+	return recommendations, nil
+}
+
+// PredictRating é a média das notas ponderada pela similaridade de cada
+// crítico, puxada para uma nota neutra quando o peso total é pequeno.
+// Devolve também o peso total, usado para desempatar.
+func PredictRating(ratings []models.CriticRating) (float64, float64) {
+	sum := neutralRating * neutralRatingWeight
+	weight := neutralRatingWeight
+	for _, rating := range ratings {
+		sum += rating.Similarity * rating.Rating
+		weight += rating.Similarity
+	}
+	return sum / weight, weight - neutralRatingWeight
+}
+
+func hasHighRating(ratings []models.CriticRating) bool {
+	for _, rating := range ratings {
+		if rating.Rating >= highRating {
+			return true
+		}
+	}
+	return false
+}
+
+// As notas já chegam ordenadas da mais à menos parecida com o usuário.
+func explainRecommendation(ratings []models.CriticRating) []string {
+	explanations := []string{}
+	for _, rating := range ratings {
+		if len(explanations) >= maxExplanations {
+			break
+		}
+		if rating.Rating < highRating {
+			continue
+		}
+
+		name := rating.DisplayName
+		if name == "" {
+			name = rating.Critic
+		}
+		explanation := fmt.Sprintf("nota %s de %s", strconv.FormatFloat(rating.Rating, 'f', -1, 64), name)
+		if rating.Agreement != nil {
+			explanation += fmt.Sprintf(", que concorda com você em %.0f%% das vezes", *rating.Agreement*100)
+		}
+		explanations = append(explanations, explanation)
+	}
+	return explanations
+}
+
+func inDecade(year string, decade int) bool {
+	value, err := strconv.Atoi(year)
+	return err == nil && value >= decade && value < decade+10
+}
+
+// Os gêneros ficam gravados como "Drama, Comédia", em português.
+func hasGenre(genres, genre string) bool {
+	for _, name := range strings.Split(genres, ",") {
+		if strings.EqualFold(strings.TrimSpace(name), strings.TrimSpace(genre)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,279 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"letterboxd-viewer-backend/internal/models"
+	"letterboxd-viewer-backend/internal/repositories"
+)
+
+// Pesos da pontuação de conteúdo. Direção em comum pesa mais que um gênero
+// em comum; elenco e palavras-chave somam até um teto para que um filme com
+// muitos créditos não domine a lista.
+const (
+	similarTopCast   = 10
+	genreWeight      = 2.0
+	directorWeight   = 3.0
+	castWeight       = 0.75
+	maxCastScore     = 3.0
+	keywordWeight    = 0.5
+	maxKeywordScore  = 3.0
+	sameDecadeWeight = 1.0
+	maxReasonNames   = 3
+	relatedCacheTTL  = 7 * 24 * time.Hour
+)
+
+// As sugestões do TMDb entram com peso decrescente pela posição na lista.
+var relatedSources = []struct {
+	Kind   string
+	Weight float64
+	Reason string
+}{
+	{Kind: "recommendations", Weight: 2.0, Reason: "recomendado pelo TMDb"},
+	{Kind: "similar", Weight: 1.5, Reason: "parecido segundo o TMDb"},
+}
+
+// SimilarMovies pontua os filmes de todos os diários pelos metadados em
+// comum com o filme de referência e, com useTMDb, mistura as listas de
+// recomendações e de filmes parecidos do TMDb. Os filmes do diário de userID
+// vêm marcados como vistos.
+func (s *RecommendationService) SimilarMovies(ctx context.Context, userID int, movie *models.Movie, useTMDb bool, limit int) ([]models.SimilarMovie, error) {
+	if useTMDb {
+		s.ensureMetadata(ctx, movie.TMDBId)
+	}
+
+	features, err := s.Recommendations.GetSharedFeatures(movie.TMDBId, similarTopCast)
+	if err != nil {
+		return nil, err
+	}
+
+	shared := map[string]map[string][]string{}
+	for _, feature := range features {
+		if shared[feature.TMDBId] == nil {
+			shared[feature.TMDBId] = map[string][]string{}
+		}
+		shared[feature.TMDBId][feature.Kind] = append(shared[feature.TMDBId][feature.Kind], feature.Name)
+	}
+
+	related := map[string][]models.SimilarMovie{}
+	if useTMDb {
+		for _, source := range relatedSources {
+			movies, err := s.relatedMovies(ctx, movie.TMDBId, source.Kind)
+			if err != nil {
+				s.Logger.Printf("Erro ao buscar %s do TMDb para o filme %s: %v", source.Kind, movie.TMDBId, err)
+				continue
+			}
+			related[source.Kind] = movies
+		}
+	}
+
+	// Só interessam os filmes dos diários que têm algo em comum com o de
+	// referência ou que o TMDb indicou; os demais nunca pontuariam.
+	ids := make([]string, 0, len(shared))
+	for tmdbId := range shared {
+		ids = append(ids, tmdbId)
+	}
+	for _, movies := range related {
+		for _, item := range movies {
+			ids = append(ids, item.TMDBId)
+		}
+	}
+
+	candidates, err := s.Recommendations.GetSimilarCandidates(userID, movie.TMDBId, ids, splitGenres(movie.Genre))
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]*models.SimilarMovie, len(candidates))
+	for i := range candidates {
+		candidate := &candidates[i]
+		candidate.Reasons = []string{}
+		scoreContent(movie, candidate, shared[candidate.TMDBId])
+		byID[candidate.TMDBId] = candidate
+	}
+
+	for _, source := range relatedSources {
+		movies := related[source.Kind]
+		for rank, item := range movies {
+			if item.TMDBId == movie.TMDBId {
+				continue
+			}
+			candidate, ok := byID[item.TMDBId]
+			if !ok {
+				item.Reasons = []string{}
+				candidate = &item
+				byID[item.TMDBId] = candidate
+			}
+			candidate.Score += source.Weight * (1 - float64(rank)/float64(len(movies)))
+			candidate.Reasons = append(candidate.Reasons, source.Reason)
+		}
+	}
+
+	similar := make([]models.SimilarMovie, 0, len(byID))
+	for _, candidate := range byID {
+		if candidate.Score > 0 {
+			candidate.Score = math.Round(candidate.Score*100) / 100
+			similar = append(similar, *candidate)
+		}
+	}
+
+	sort.Slice(similar, func(i, j int) bool {
+		if similar[i].Score != similar[j].Score {
+			return similar[i].Score > similar[j].Score
+		}
+		return similar[i].TMDBId < similar[j].TMDBId
+	})
+
+	if len(similar) > limit {
+		similar = similar[:limit]
+	}
+	return similar, nil
+}
+
+// scoreContent só pontua a década quando há algo mais em comum; sozinha,
+// ela aproximaria metade do diário.
+func scoreContent(movie *models.Movie, candidate *models.SimilarMovie, shared map[string][]string) {
+	if genres, score := sharedGenres(movie.Genre, candidate.Genre); score > 0 {
+		candidate.Score += genreWeight * score
+		candidate.Reasons = append(candidate.Reasons, "gêneros em comum: "+joinNames(genres))
+	}
+	if directors := shared[models.SharedDirector]; len(directors) > 0 {
+		candidate.Score += directorWeight
+		candidate.Reasons = append(candidate.Reasons, "direção de "+joinNames(directors))
+	}
+	if cast := shared[models.SharedCast]; len(cast) > 0 {
+		candidate.Score += math.Min(castWeight*float64(len(cast)), maxCastScore)
+		candidate.Reasons = append(candidate.Reasons, "elenco em comum: "+joinNames(cast))
+	}
+	if keywords := shared[models.SharedKeyword]; len(keywords) > 0 {
+		candidate.Score += math.Min(keywordWeight*float64(len(keywords)), maxKeywordScore)
+		candidate.Reasons = append(candidate.Reasons, "palavras-chave em comum: "+joinNames(keywords))
+	}
+
+	if candidate.Score > 0 {
+		if decade, ok := decadeOf(movie.Year); ok {
+			if other, ok := decadeOf(candidate.Year); ok && other == decade {
+				candidate.Score += sameDecadeWeight
+				candidate.Reasons = append(candidate.Reasons, fmt.Sprintf("também dos anos %d", decade))
+			}
+		}
+	}
+}
+
+// sharedGenres devolve os gêneros em comum e o índice de Jaccard entre as
+// duas listas.
+func sharedGenres(a, b string) ([]string, float64) {
+	seen := map[string]bool{}
+	for _, genre := range strings.Split(a, ",") {
+		if genre = strings.TrimSpace(genre); genre != "" {
+			seen[strings.ToLower(genre)] = true
+		}
+	}
+
+	var common []string
+	union := len(seen)
+	for _, genre := range strings.Split(b, ",") {
+		genre = strings.TrimSpace(genre)
+		if genre == "" {
+			continue
+		}
+		if seen[strings.ToLower(genre)] {
+			common = append(common, genre)
+		} else {
+			union++
+		}
+	}
+
+	if len(common) == 0 {
+		return nil, 0
+	}
+	return common, float64(len(common)) / float64(union)
+}
+
+// splitGenres devolve os gêneros da lista separada por vírgulas, em
+// minúsculas.
+func splitGenres(genre string) []string {
+	var genres []string
+	for _, name := range strings.Split(genre, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			genres = append(genres, strings.ToLower(name))
+		}
+	}
+	return genres
+}
+
+func decadeOf(year string) (int, bool) {
+	value, err := strconv.Atoi(year)
+	if err != nil || value <= 0 {
+		return 0, false
+	}
+	return value - value%10, true
+}
+
+func joinNames(names []string) string {
+	if len(names) > maxReasonNames {
+		return strings.Join(names[:maxReasonNames], ", ") + fmt.Sprintf(" e mais %d", len(names)-maxReasonNames)
+	}
+	return strings.Join(names, ", ")
+}
+
+// ensureMetadata busca no TMDb, uma única vez, os créditos e as
+// palavras-chave de filmes importados antes de elas serem guardadas.
+func (s *RecommendationService) ensureMetadata(ctx context.Context, tmdbId string) {
+	if _, err := repositories.GetCredits(s.DB, tmdbId); errors.Is(err, sql.ErrNoRows) {
+		credits, err := s.TMDBService.GetMovieCredits(ctx, tmdbId)
+		if err == nil {
+			err = repositories.SaveCredits(s.DB, tmdbId, credits)
+		}
+		if err != nil {
+			s.Logger.Printf("Erro ao completar créditos do filme %s: %v", tmdbId, err)
+		}
+	}
+
+	if found, err := repositories.KeywordsFetched(s.DB, tmdbId); err == nil && !found {
+		keywords, err := s.TMDBService.GetMovieKeywords(ctx, tmdbId)
+		if err == nil {
+			err = repositories.SaveKeywords(s.DB, tmdbId, keywords)
+		}
+		if err != nil {
+			s.Logger.Printf("Erro ao completar palavras-chave do filme %s: %v", tmdbId, err)
+		}
+	}
+}
+
+func (s *RecommendationService) relatedMovies(ctx context.Context, tmdbId, kind string) ([]models.SimilarMovie, error) {
+	key := fmt.Sprintf("movie:%s:%s", tmdbId, kind)
+
+	payload, found, err := repositories.GetCached(s.DB, key, relatedCacheTTL)
+	if err != nil {
+		s.Logger.Printf("Erro ao ler cache de %s do filme %s: %v", kind, tmdbId, err)
+	}
+	if found {
+		var movies []models.SimilarMovie
+		if err := json.Unmarshal(payload, &movies); err == nil {
+			return movies, nil
+		}
+	}
+
+	movies, err := s.TMDBService.GetRelatedMovies(ctx, tmdbId, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	if payload, err := json.Marshal(movies); err == nil {
+		if err := repositories.SetCached(s.DB, key, payload); err != nil {
+			s.Logger.Printf("Erro ao gravar cache de %s do filme %s: %v", kind, tmdbId, err)
+		}
+	}
+
+	return movies, nil
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"database/sql"
+	"log"
+	"math"
+	"sort"
+
+	"letterboxd-viewer-backend/internal/models"
+	"letterboxd-viewer-backend/internal/repositories"
+)
+
+const (
+	// Abaixo disso as correlações dizem mais sobre o acaso do que sobre gosto.
+	minCorrelationOverlap = 3
+	// Notas a até meia estrela de distância contam como concordância.
+	agreementTolerance = 0.5
+)
+
+// SimilarityService mantém a tabela de similaridade entre cada usuário e os
+// críticos que ele acompanha. Só os pares afetados por uma sincronização são
+// recalculados.
+type SimilarityService struct {
+	Similarities *repositories.SimilarityRepository
+	Critics      *repositories.CriticRepository
+	Logger       *log.Logger
+}
+
+func NewSimilarityService(db *sql.DB, logger *log.Logger) *SimilarityService {
+	return &SimilarityService{
+		Similarities: repositories.NewSimilarityRepository(db),
+		Critics:      repositories.NewCriticRepository(db),
+		Logger:       logger,
+	}
+}
+
+func (s *SimilarityService) Recompute(userID int, critic string) error {
+	pairs, err := s.Similarities.GetSharedRatings(userID, critic)
+	if err != nil {
+		return err
+	}
+
+	sim := CompareRatings(pairs)
+	sim.Critic = critic
+	return s.Similarities.SaveSimilarity(userID, sim)
+}
+
+// GetSimilarities lista os críticos acompanhados do mais ao menos parecido,
+// ignorando os que têm menos de minOverlap filmes em comum com o usuário.
+func (s *SimilarityService) GetSimilarities(userID, minOverlap int) ([]models.CriticSimilarity, error) {
+	return s.Similarities.GetSimilarities(userID, minOverlap)
+}
+
+// RecomputeForUser é chamado quando o diário do usuário muda.
+func (s *SimilarityService) RecomputeForUser(userID int) error {
+	critics, err := s.Critics.GetCriticUsernames(userID)
+	if err != nil {
+		return err
+	}
+	for _, critic := range critics {
+		if err := s.Recompute(userID, critic); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RecomputeForCritic é chamado quando o feed do crítico traz novidades.
+func (s *SimilarityService) RecomputeForCritic(critic string) error {
+	userIDs, err := s.Critics.GetCriticFollowers(critic)
+	if err != nil {
+		return err
+	}
+	for _, userID := range userIDs {
+		if err := s.Recompute(userID, critic); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CompareRatings calcula a sobreposição, as correlações de Pearson e
+// Spearman, a diferença absoluta média e a concordância entre os pares
+// (minha nota, nota do crítico).
+func CompareRatings(pairs [][2]float64) *models.CriticSimilarity {
+	sim := &models.CriticSimilarity{Overlap: len(pairs)}
+	if len(pairs) == 0 {
+		return sim
+	}
+
+	mine := make([]float64, len(pairs))
+	theirs := make([]float64, len(pairs))
+	var diff float64
+	var agreed int
+	for i, pair := range pairs {
+		mine[i], theirs[i] = pair[0], pair[1]
+		diff += math.Abs(pair[0] - pair[1])
+		if math.Abs(pair[0]-pair[1]) <= agreementTolerance {
+			agreed++
+		}
+	}
+	meanAbsDiff := diff / float64(len(pairs))
+	agreement := float64(agreed) / float64(len(pairs))
+	sim.MeanAbsDiff = &meanAbsDiff
+	sim.Agreement = &agreement
+
+	if len(pairs) >= minCorrelationOverlap {
+		sim.Pearson = pearson(mine, theirs)
+		sim.Spearman = pearson(ranks(mine), ranks(theirs))
+	}
+	return sim
+}
+
+// pearson é nil quando uma das séries é constante.
+func pearson(x, y []float64) *float64 {
+	n := float64(len(x))
+	var sumX, sumY float64
+	for i := range x {
+		sumX += x[i]
+		sumY += y[i]
+	}
+	meanX, meanY := sumX/n, sumY/n
+
+	var cov, varX, varY float64
+	for i := range x {
+		dx, dy := x[i]-meanX, y[i]-meanY
+		cov += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+	if varX == 0 || varY == 0 {
+		return nil
+	}
+
+	r := cov / math.Sqrt(varX*varY)
+	r = math.Max(-1, math.Min(1, r))
+	return &r
+}
+
+// ranks usa a média das posições para notas empatadas, o que é comum numa
+// escala de meia em meia estrela.
+func ranks(values []float64) []float64 {
+	order := make([]int, len(values))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool { return values[order[a]] < values[order[b]] })
+
+	result := make([]float64, len(values))
+	for start := 0; start < len(order); {
+		end := start
+		for end+1 < len(order) && values[order[end+1]] == values[order[start]] {
+			end++
+		}
+		rank := float64(start+end)/2 + 1
+		for k := start; k <= end; k++ {
+			result[order[k]] = rank
+		}
+		start = end + 1
+	}
+	return result
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRanks(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   []float64
+	}{
+		{name: "sem empates", values: []float64{3, 1, 2}, want: []float64{3, 1, 2}},
+		{name: "empate em meia estrela", values: []float64{3.5, 4, 3.5, 5, 2}, want: []float64{2.5, 4, 2.5, 5, 1}},
+		{name: "todos empatados", values: []float64{4, 4, 4}, want: []float64{2, 2, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ranks(tt.values)
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Fatalf("ranks(%v) = %v, esperado %v", tt.values, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestCompareRatings(t *testing.T) {
+	value := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name        string
+		pairs       [][2]float64
+		pearson     *float64
+		spearman    *float64
+		meanAbsDiff *float64
+		agreement   *float64
+	}{
+		{
+			name: "sem filmes em comum",
+		},
+		{
+			name:        "abaixo da sobreposição mínima",
+			pairs:       [][2]float64{{1, 1}, {2, 2.5}},
+			meanAbsDiff: value(0.25),
+			agreement:   value(1),
+		},
+		{
+			name:        "série constante",
+			pairs:       [][2]float64{{3, 1}, {3, 2}, {3, 4}},
+			meanAbsDiff: value(4.0 / 3),
+			agreement:   value(0),
+		},
+		{
+			name:        "correlação perfeita",
+			pairs:       [][2]float64{{1, 2}, {2, 3}, {3, 4}},
+			pearson:     value(1),
+			spearman:    value(1),
+			meanAbsDiff: value(1),
+			agreement:   value(0),
+		},
+		{
+			name:        "correlação inversa",
+			pairs:       [][2]float64{{1, 3}, {2, 2}, {3, 1}},
+			pearson:     value(-1),
+			spearman:    value(-1),
+			meanAbsDiff: value(4.0 / 3),
+			agreement:   value(1.0 / 3),
+		},
+		{
+			name:        "ordem trocada no meio",
+			pairs:       [][2]float64{{1, 1}, {2, 3}, {3, 2}, {4, 4}},
+			pearson:     value(0.8),
+			spearman:    value(0.8),
+			meanAbsDiff: value(0.5),
+			agreement:   value(0.5),
+		},
+		{
+			// Postos: [1.5 1.5 3 4] e [1 2.5 2.5 4].
+			name:        "empates nas duas séries",
+			pairs:       [][2]float64{{3, 2}, {3, 3}, {4, 3}, {5, 5}},
+			pearson:     value(3.25 / math.Sqrt(2.75*4.75)),
+			spearman:    value(3.75 / 4.5),
+			meanAbsDiff: value(0.5),
+			agreement:   value(0.5),
+		},
+		{
+			name:        "meia estrela de diferença concorda",
+			pairs:       [][2]float64{{3, 3.5}, {2, 3}, {4, 4}},
+			pearson:     value(1),
+			spearman:    value(1),
+			meanAbsDiff: value(0.5),
+			agreement:   value(2.0 / 3),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sim := CompareRatings(tt.pairs)
+			if sim.Overlap != len(tt.pairs) {
+				t.Errorf("Overlap = %d, esperado %d", sim.Overlap, len(tt.pairs))
+			}
+			assertFloat(t, "Pearson", sim.Pearson, tt.pearson)
+			assertFloat(t, "Spearman", sim.Spearman, tt.spearman)
+			assertFloat(t, "MeanAbsDiff", sim.MeanAbsDiff, tt.meanAbsDiff)
+			assertFloat(t, "Agreement", sim.Agreement, tt.agreement)
+		})
+	}
+}
+
+func TestPearsonStaysWithinBounds(t *testing.T) {
+	// Séries colineares com valores que não são exatos em ponto flutuante.
+	x := []float64{0.1, 0.2, 0.3, 0.7, 1.1, 1.3}
+	y := make([]float64, len(x))
+	z := make([]float64, len(x))
+	for i := range x {
+		y[i] = 3*x[i] + 0.1
+		z[i] = -7*x[i] + 0.3
+	}
+
+	if r := pearson(x, y); r == nil || *r > 1 || math.Abs(*r-1) > 1e-9 {
+		t.Errorf("pearson(x, 3x) = %v, esperado 1", r)
+	}
+	if r := pearson(x, z); r == nil || *r < -1 || math.Abs(*r+1) > 1e-9 {
+		t.Errorf("pearson(x, -7x) = %v, esperado -1", r)
+	}
+}
+
+func assertFloat(t *testing.T, name string, got, want *float64) {
+	t.Helper()
+	switch {
+	case got == nil && want == nil:
+	case got == nil || want == nil:
+		t.Errorf("%s = %v, esperado %v", name, got, want)
+	case math.Abs(*got-*want) > 1e-9:
+		t.Errorf("%s = %v, esperado %v", name, *got, *want)
+	}
+}
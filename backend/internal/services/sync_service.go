@@ -0,0 +1,337 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"letterboxd-viewer-backend/internal/models"
+	"letterboxd-viewer-backend/internal/repositories"
+
+	"github.com/mmcdole/gofeed"
+)
+
+const feedFetchTimeout = 30 * time.Second
+
+// feedClient segue redirecionamentos só dentro do mesmo host, para que uma
+// URL validada não acabe baixando o feed de outro lugar.
+var feedClient = &http.Client{
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 10 {
+			return errors.New("redirecionamentos demais")
+		}
+		if req.URL.Host != via[0].URL.Host {
+			return fmt.Errorf("redirecionamento para outro host recusado: %s", req.URL.Host)
+		}
+		return nil
+	},
+}
+
+var ErrNoFeedSource = errors.New("nenhum feed RSS configurado para o usuário; informe o usuário do Letterboxd")
+
+// SyncService concentra a importação do diário (feed RSS e export do
+// Letterboxd) e o enriquecimento com o TMDb, compartilhados pela API e
+// pelos comandos de linha de comando.
+type SyncService struct {
+	DB          *sql.DB
+	TMDBService *TMDBService
+	Similarity  *SimilarityService
+	Logger      *log.Logger
+	FeedPath    string
+}
+
+type SyncResult struct {
+	Inserted int      `json:"inserted"`
+	Skipped  int      `json:"skipped"`
+	Failed   []string `json:"failed"`
+}
+
+type EnrichResult struct {
+	Enriched int      `json:"enriched"`
+	Failed   []string `json:"failed"`
+}
+
+// feedPath aponta para o arquivo .rss gerado pelo próprio Letterboxd e vale
+// apenas para o dono da instância; os demais usuários usam o próprio feed.
+func NewSyncService(db *sql.DB, tmdbService *TMDBService, feedPath string, logger *log.Logger) *SyncService {
+	return &SyncService{
+		DB:          db,
+		TMDBService: tmdbService,
+		Similarity:  NewSimilarityService(db, logger),
+		Logger:      logger,
+		FeedPath:    feedPath,
+	}
+}
+
+// FeedSource resolve de onde vem o diário do usuário: o arquivo RSS local
+// definido pela linha de comando, o feed público do Letterboxd ou, para o
+// dono da instância, o RSS_FILE_PATH global.
+func (s *SyncService) FeedSource(user *models.User) (string, error) {
+	if user.RSSFilePath != "" {
+		return user.RSSFilePath, nil
+	}
+	if user.LetterboxdUsername != "" {
+		return LetterboxdFeedURL(user.LetterboxdUsername), nil
+	}
+
+	if s.FeedPath != "" {
+		owner, err := repositories.GetDefaultUser(s.DB)
+		if err != nil && err != sql.ErrNoRows {
+			return "", err
+		}
+		if owner != nil && owner.ID == user.ID {
+			return s.FeedPath, nil
+		}
+	}
+	return "", ErrNoFeedSource
+}
+
+func LetterboxdFeedURL(username string) string {
+	return "https://letterboxd.com/" + username + "/rss/"
+}
+
+// SyncUser importa o feed do usuário para o diário dele. source, quando
+// informado, substitui o feed configurado (arquivo local ou URL).
+func (s *SyncService) SyncUser(ctx context.Context, user *models.User, source string) (*SyncResult, error) {
+	if source == "" {
+		var err error
+		if source, err = s.FeedSource(user); err != nil {
+			return nil, err
+		}
+	}
+
+	feed, err := s.parseFeed(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.SyncFeed(ctx, user.ID, feed)
+}
+
+func (s *SyncService) parseFeed(ctx context.Context, source string) (*gofeed.Feed, error) {
+	if strings.HasPrefix(source, "https://") || strings.HasPrefix(source, "http://") {
+		return FetchFeed(ctx, source)
+	}
+
+	file, err := os.Open(source)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler o arquivo RSS: %w", err)
+	}
+	defer file.Close()
+
+	feed, err := gofeed.NewParser().Parse(file)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao fazer parse do RSS: %w", err)
+	}
+	return feed, nil
+}
+
+// FetchFeed baixa e interpreta um feed remoto em até feedFetchTimeout.
+func FetchFeed(ctx context.Context, url string) (*gofeed.Feed, error) {
+	ctx, cancel := context.WithTimeout(ctx, feedFetchTimeout)
+	defer cancel()
+
+	parser := gofeed.NewParser()
+	parser.Client = feedClient
+
+	feed, err := parser.ParseURLWithContext(url, ctx)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao baixar o RSS: %w", err)
+	}
+	return feed, nil
+}
+
+func (s *SyncService) SyncFeed(ctx context.Context, userID int, feed *gofeed.Feed) (*SyncResult, error) {
+	result := &SyncResult{Failed: []string{}}
+
+	for _, item := range feed.Items {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		exists, err := repositories.CheckMovieExists(s.DB, userID, item.GUID)
+		if err != nil {
+			s.Logger.Printf("Erro ao verificar filme no banco de dados: %v", err)
+			result.Failed = append(result.Failed, item.GUID)
+			continue
+		}
+		if exists {
+			result.Skipped++
+			continue
+		}
+
+		movie := s.MovieFromFeedItem(item)
+		movie.UserID = userID
+		if movie.TMDBId != "" {
+			// A mesma sessão pode já ter vindo de um export importado pela linha de comando.
+			watched, err := repositories.MovieWatchedOn(s.DB, userID, movie.TMDBId, movie.WatchedDate)
+			if err == nil && watched {
+				result.Skipped++
+				continue
+			}
+
+			// Sem o TMDb o filme ainda entra no diário, só que sem os dados extras.
+			s.EnrichMovie(ctx, movie)
+		}
+
+		if err := repositories.InsertMovie(s.DB, movie); err != nil {
+			s.Logger.Printf("Erro ao inserir filme no banco de dados: %v", err)
+			result.Failed = append(result.Failed, movie.Title)
+			continue
+		}
+		s.Logger.Printf("Filme %s inserido com sucesso", movie.Title)
+		result.Inserted++
+	}
+
+	if result.Inserted > 0 {
+		s.updateSimilarity(userID)
+	}
+
+	return result, nil
+}
+
+// updateSimilarity recalcula a similaridade com os críticos depois que o
+// diário muda. Uma falha aqui não desfaz a sincronização.
+func (s *SyncService) updateSimilarity(userID int) {
+	if err := s.Similarity.RecomputeForUser(userID); err != nil {
+		s.Logger.Printf("Erro ao recalcular similaridade com os críticos: %v", err)
+	}
+}
+
+func (s *SyncService) MovieFromFeedItem(item *gofeed.Item) *models.Movie {
+	guid := item.GUID
+
+	watchedDate := feedExtension(item, "letterboxd", "watchedDate")
+	if watchedDate == "" {
+		s.Logger.Printf("watchedDate não encontrado para o item com GUID: %s", guid)
+	}
+	memberRating := feedExtension(item, "letterboxd", "memberRating")
+	if memberRating == "" {
+		s.Logger.Printf("memberRating não encontrado para o item com GUID: %s", guid)
+	}
+
+	return &models.Movie{
+		Title:        feedExtension(item, "letterboxd", "filmTitle"),
+		Year:         feedExtension(item, "letterboxd", "filmYear"),
+		WatchedDate:  watchedDate,
+		MemberRating: memberRating,
+		Rewatch:      feedExtension(item, "letterboxd", "rewatch") == "Yes",
+		Description:  item.Description,
+		GUID:         guid,
+		TMDBId:       feedExtension(item, "tmdb", "movieId"),
+		Source:       models.SourceLetterboxd,
+	}
+}
+
+func (s *SyncService) EnrichMovie(ctx context.Context, movie *models.Movie) error {
+	tmdbInfo, err := s.TMDBService.GetMovieInfo(ctx, movie.TMDBId)
+	if err != nil {
+		s.Logger.Printf("Erro ao buscar informações do TMDb: %v", err)
+		return err
+	}
+	applyTMDBInfo(movie, tmdbInfo)
+
+	// Entradas manuais não trazem título e ano do feed do Letterboxd.
+	if movie.Title == "" {
+		movie.Title = tmdbInfo.Title
+	}
+	if movie.Year == "" && len(tmdbInfo.ReleaseDate) >= 4 {
+		movie.Year = tmdbInfo.ReleaseDate[:4]
+	}
+
+	credits, err := s.TMDBService.GetMovieCredits(ctx, movie.TMDBId)
+	if err != nil {
+		s.Logger.Printf("Erro ao buscar créditos do TMDb: %v", err)
+	} else {
+		movie.Director = credits.Directors()
+		if err := repositories.SaveCredits(s.DB, movie.TMDBId, credits); err != nil {
+			s.Logger.Printf("Erro ao salvar créditos no banco de dados: %v", err)
+		}
+	}
+
+	keywords, err := s.TMDBService.GetMovieKeywords(ctx, movie.TMDBId)
+	if err != nil {
+		s.Logger.Printf("Erro ao buscar palavras-chave do TMDb: %v", err)
+	} else if err := repositories.SaveKeywords(s.DB, movie.TMDBId, keywords); err != nil {
+		s.Logger.Printf("Erro ao salvar palavras-chave no banco de dados: %v", err)
+	}
+
+	return nil
+}
+
+// EnrichMovies busca no TMDb os filmes com dados incompletos (ou todos, com
+// all=true) e atualiza o banco. Filmes sem TMDb id são procurados pelo título.
+func (s *SyncService) EnrichMovies(ctx context.Context, all bool, limit int) (*EnrichResult, error) {
+	result := &EnrichResult{Failed: []string{}}
+
+	movies, err := repositories.GetMoviesToEnrich(s.DB, all, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range movies {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		movie := &movies[i]
+		if movie.TMDBId == "" {
+			tmdbId, err := s.TMDBService.SearchMovieID(ctx, movie.Title, movie.Year)
+			if err != nil {
+				s.Logger.Printf("Erro ao identificar %q (%s) no TMDb: %v", movie.Title, movie.Year, err)
+				result.Failed = append(result.Failed, movie.GUID)
+				continue
+			}
+			movie.TMDBId = tmdbId
+		}
+
+		if err := s.EnrichMovie(ctx, movie); err != nil {
+			result.Failed = append(result.Failed, movie.GUID)
+			continue
+		}
+		if err := repositories.UpdateMovieTMDBInfo(s.DB, movie); err != nil {
+			s.Logger.Printf("Erro ao atualizar filme %s: %v", movie.GUID, err)
+			result.Failed = append(result.Failed, movie.GUID)
+			continue
+		}
+		result.Enriched++
+	}
+
+	return result, nil
+}
+
+func applyTMDBInfo(movie *models.Movie, tmdbInfo *models.Movie) {
+	movie.Plot = tmdbInfo.Plot
+	movie.Genre = tmdbInfo.Genre
+	movie.Director = tmdbInfo.Director
+	movie.IMDBRating = tmdbInfo.IMDBRating
+	movie.Runtime = tmdbInfo.Runtime
+	movie.ReleaseDate = tmdbInfo.ReleaseDate
+	movie.Budget = tmdbInfo.Budget
+	movie.Revenue = tmdbInfo.Revenue
+	movie.Tagline = tmdbInfo.Tagline
+	movie.Status = tmdbInfo.Status
+	movie.OriginalLanguage = tmdbInfo.OriginalLanguage
+	movie.ProductionCompanies = tmdbInfo.ProductionCompanies
+	movie.SpokenLanguages = tmdbInfo.SpokenLanguages
+	movie.PosterPath = tmdbInfo.PosterPath
+	movie.BackdropPath = tmdbInfo.BackdropPath
+	movie.Homepage = tmdbInfo.Homepage
+	movie.OriginalTitle = tmdbInfo.OriginalTitle
+	movie.ProductionCountries = tmdbInfo.ProductionCountries
+}
+
+func feedExtension(item *gofeed.Item, namespace, name string) string {
+	if ext, ok := item.Extensions[namespace]; ok {
+		if values := ext[name]; len(values) > 0 {
+			return values[0].Value
+		}
+	}
+	return ""
+}
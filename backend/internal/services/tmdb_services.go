@@ -1,42 +1,24 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"letterboxd-viewer-backend/internal/models"
 	"log"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 )
 
-type MovieCredits struct {
-	ID   int          `json:"id"`
-	Cast []CastMember `json:"cast"`
-	Crew []CrewMember `json:"crew"`
-}
-
-type CastMember struct {
-	CastID      int     `json:"cast_id"`
-	Character   string  `json:"character"`
-	CreditID    string  `json:"credit_id"`
-	Gender      int     `json:"gender"`
-	ID          int     `json:"id"`
-	Name        string  `json:"name"`
-	Order       int     `json:"order"`
-	ProfilePath *string `json:"profile_path"`
-}
-
-type CrewMember struct {
-	CreditID    string  `json:"credit_id"`
-	Department  string  `json:"department"`
-	Gender      int     `json:"gender"`
-	ID          int     `json:"id"`
-	Job         string  `json:"job"`
-	Name        string  `json:"name"`
-	ProfilePath *string `json:"profile_path"`
-}
+var (
+	ErrMovieNotFound  = errors.New("filme não encontrado no TMDb")
+	ErrAmbiguousMovie = errors.New("mais de um filme no TMDb corresponde ao título e ano")
+)
 
 type TMDBService struct {
 	AccessToken string
@@ -46,16 +28,20 @@ type TMDBService struct {
 }
 
 type TMDBMovieResponse struct {
-	Title       string  `json:"title"`
-	Overview    string  `json:"overview"`
-	VoteAverage float64 `json:"vote_average"`
-	Runtime     int     `json:"runtime"`
-	Genres      []struct {
+	Title         string  `json:"title"`
+	OriginalTitle string  `json:"original_title"`
+	Overview      string  `json:"overview"`
+	VoteAverage   float64 `json:"vote_average"`
+	Runtime       int     `json:"runtime"`
+	Genres        []struct {
 		Name string `json:"name"`
 	} `json:"genres"`
 	ProductionCompanies []struct {
 		Name string `json:"name"`
 	} `json:"production_companies"`
+	ProductionCountries []struct {
+		Name string `json:"name"`
+	} `json:"production_countries"`
 	ReleaseDate      string `json:"release_date"`
 	Budget           int    `json:"budget"`
 	Revenue          int    `json:"revenue"`
@@ -81,34 +67,42 @@ func NewTMDBService(accessToken string) *TMDBService {
 	}
 }
 
-func (s *TMDBService) getMovieInfoByLanguage(tmdbId, language string) (*models.Movie, error) {
+func (s *TMDBService) getMovieInfoByLanguage(ctx context.Context, tmdbId, language string) (*models.Movie, error) {
 	url := fmt.Sprintf("%s/movie/%s?language=%s", s.BaseURL, tmdbId, language)
 
-	req, err := http.NewRequest("GET", url, nil)
+	var tmdbResponse TMDBMovieResponse
+	if err := s.getJSON(ctx, url, &tmdbResponse); err != nil {
+		return nil, err
+	}
+
+	return s.convertResponseToMovie(&tmdbResponse), nil
+}
+
+func (s *TMDBService) getJSON(ctx context.Context, url string, target any) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao criar request: %w", err)
+		return fmt.Errorf("erro ao criar request: %w", err)
 	}
 
 	s.setRequestHeaders(req)
 
 	response, err := s.Client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("erro na requisição: %w", err)
+		return fmt.Errorf("erro na requisição: %w", err)
 	}
 	defer response.Body.Close()
 
 	if response.StatusCode != http.StatusOK {
 		body, _ := ioutil.ReadAll(response.Body)
 		s.Logger.Printf("Erro na resposta do TMDb: %s", string(body))
-		return nil, fmt.Errorf("erro de status code: %d %s", response.StatusCode, response.Status)
+		return fmt.Errorf("erro de status code: %d %s", response.StatusCode, response.Status)
 	}
 
-	var tmdbResponse TMDBMovieResponse
-	if err := json.NewDecoder(response.Body).Decode(&tmdbResponse); err != nil {
-		return nil, fmt.Errorf("erro ao decodificar resposta: %w", err)
+	if err := json.NewDecoder(response.Body).Decode(target); err != nil {
+		return fmt.Errorf("erro ao decodificar resposta: %w", err)
 	}
 
-	return s.convertResponseToMovie(&tmdbResponse), nil
+	return nil
 }
 
 func (s *TMDBService) setRequestHeaders(req *http.Request) {
@@ -119,6 +113,7 @@ func (s *TMDBService) setRequestHeaders(req *http.Request) {
 func (s *TMDBService) convertResponseToMovie(response *TMDBMovieResponse) *models.Movie {
 	movie := &models.Movie{
 		Title:            response.Title,
+		OriginalTitle:    response.OriginalTitle,
 		Plot:             response.Overview,
 		IMDBRating:       fmt.Sprintf("%.1f", response.VoteAverage),
 		Runtime:          response.Runtime,
@@ -145,6 +140,12 @@ func (s *TMDBService) convertResponseToMovie(response *TMDBMovieResponse) *model
 	}
 	movie.ProductionCompanies = strings.Join(productionCompanies, ", ")
 
+	var productionCountries []string
+	for _, country := range response.ProductionCountries {
+		productionCountries = append(productionCountries, country.Name)
+	}
+	movie.ProductionCountries = strings.Join(productionCountries, ", ")
+
 	var languages []string
 	for _, language := range response.SpokenLanguages {
 		languages = append(languages, language.Name)
@@ -154,13 +155,13 @@ func (s *TMDBService) convertResponseToMovie(response *TMDBMovieResponse) *model
 	return movie
 }
 
-func (s *TMDBService) GetMovieInfo(tmdbId string) (*models.Movie, error) {
-	moviePTBR, err := s.getMovieInfoByLanguage(tmdbId, "pt-BR")
+func (s *TMDBService) GetMovieInfo(ctx context.Context, tmdbId string) (*models.Movie, error) {
+	moviePTBR, err := s.getMovieInfoByLanguage(ctx, tmdbId, "pt-BR")
 	if err != nil {
 		return nil, fmt.Errorf("erro ao buscar informações em pt-BR: %w", err)
 	}
 
-	movieEN, err := s.getMovieInfoByLanguage(tmdbId, "en-US")
+	movieEN, err := s.getMovieInfoByLanguage(ctx, tmdbId, "en-US")
 	if err != nil {
 		return nil, fmt.Errorf("erro ao buscar informações em en-US: %w", err)
 	}
@@ -175,32 +176,155 @@ func (s *TMDBService) GetMovieInfo(tmdbId string) (*models.Movie, error) {
 	return moviePTBR, nil
 }
 
-func (s *TMDBService) GetMovieCredits(tmdbId string) (*MovieCredits, error) {
+func (s *TMDBService) GetMovieCredits(ctx context.Context, tmdbId string) (*models.MovieCredits, error) {
 	url := fmt.Sprintf("%s/movie/%s/credits", s.BaseURL, tmdbId)
 
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("erro ao criar request: %w", err)
+	var credits models.MovieCredits
+	if err := s.getJSON(ctx, url, &credits); err != nil {
+		return nil, err
 	}
 
-	s.setRequestHeaders(req)
+	return &credits, nil
+}
 
-	response, err := s.Client.Do(req)
+func (s *TMDBService) GetMovieKeywords(ctx context.Context, tmdbId string) ([]models.Keyword, error) {
+	url := fmt.Sprintf("%s/movie/%s/keywords", s.BaseURL, tmdbId)
+
+	var response struct {
+		Keywords []models.Keyword `json:"keywords"`
+	}
+	if err := s.getJSON(ctx, url, &response); err != nil {
+		return nil, err
+	}
+
+	return response.Keywords, nil
+}
+
+// GetRelatedMovies devolve a primeira página de /recommendations ou /similar
+// do TMDb para o filme.
+func (s *TMDBService) GetRelatedMovies(ctx context.Context, tmdbId, kind string) ([]models.SimilarMovie, error) {
+	url := fmt.Sprintf("%s/movie/%s/%s?language=pt-BR", s.BaseURL, tmdbId, kind)
+
+	var response struct {
+		Results []struct {
+			ID          int    `json:"id"`
+			Title       string `json:"title"`
+			ReleaseDate string `json:"release_date"`
+			PosterPath  string `json:"poster_path"`
+		} `json:"results"`
+	}
+	if err := s.getJSON(ctx, url, &response); err != nil {
+		return nil, err
+	}
+
+	movies := make([]models.SimilarMovie, 0, len(response.Results))
+	for _, result := range response.Results {
+		movie := models.SimilarMovie{
+			TMDBId:     strconv.Itoa(result.ID),
+			Title:      result.Title,
+			PosterPath: result.PosterPath,
+		}
+		if len(result.ReleaseDate) >= 4 {
+			movie.Year = result.ReleaseDate[:4]
+		}
+		movies = append(movies, movie)
+	}
+
+	return movies, nil
+}
+
+func (s *TMDBService) GetPerson(ctx context.Context, personId int) (*models.Person, error) {
+	var personPTBR models.Person
+	url := fmt.Sprintf("%s/person/%d?language=pt-BR", s.BaseURL, personId)
+	if err := s.getJSON(ctx, url, &personPTBR); err != nil {
+		return nil, fmt.Errorf("erro ao buscar pessoa em pt-BR: %w", err)
+	}
+
+	if personPTBR.Biography == "" {
+		var personEN models.Person
+		url := fmt.Sprintf("%s/person/%d?language=en-US", s.BaseURL, personId)
+		if err := s.getJSON(ctx, url, &personEN); err != nil {
+			return nil, fmt.Errorf("erro ao buscar pessoa em en-US: %w", err)
+		}
+		personPTBR.Biography = personEN.Biography
+	}
+
+	return &personPTBR, nil
+}
+
+// Ping verifica se a API do TMDb está acessível e se o token é aceito.
+func (s *TMDBService) Ping(ctx context.Context) error {
+	var response struct {
+		Images struct {
+			SecureBaseURL string `json:"secure_base_url"`
+		} `json:"images"`
+	}
+	return s.getJSON(ctx, s.BaseURL+"/configuration", &response)
+}
+
+func (s *TMDBService) SearchMovieID(ctx context.Context, title, year string) (string, error) {
+	params := url.Values{}
+	params.Set("query", title)
+	if year != "" {
+		params.Set("primary_release_year", year)
+	}
+
+	var response struct {
+		Results []tmdbSearchResult `json:"results"`
+	}
+	if err := s.getJSON(ctx, fmt.Sprintf("%s/search/movie?%s", s.BaseURL, params.Encode()), &response); err != nil {
+		return "", fmt.Errorf("erro ao buscar filme %q: %w", title, err)
+	}
+
+	id, err := matchSearchResult(response.Results, title, year)
 	if err != nil {
-		return nil, fmt.Errorf("erro na requisição: %w", err)
+		return "", fmt.Errorf("%w: %q (%s)", err, title, year)
 	}
-	defer response.Body.Close()
+	return strconv.Itoa(id), nil
+}
 
-	if response.StatusCode != http.StatusOK {
-		body, _ := ioutil.ReadAll(response.Body)
-		s.Logger.Printf("Erro na resposta do TMDb: %s", string(body))
-		return nil, fmt.Errorf("erro de status code: %d %s", response.StatusCode, response.Status)
+type tmdbSearchResult struct {
+	ID            int    `json:"id"`
+	Title         string `json:"title"`
+	OriginalTitle string `json:"original_title"`
+	ReleaseDate   string `json:"release_date"`
+}
+
+// matchSearchResult não confia no primeiro resultado da busca: com o ano
+// informado, descarta filmes de outros anos; entre os que sobram, prefere o
+// título idêntico. Remakes e homônimos sem como desempatar viram
+// ErrAmbiguousMovie.
+func matchSearchResult(results []tmdbSearchResult, title, year string) (int, error) {
+	var candidates []tmdbSearchResult
+	for _, result := range results {
+		if year == "" || strings.HasPrefix(result.ReleaseDate, year+"-") {
+			candidates = append(candidates, result)
+		}
 	}
 
-	var credits MovieCredits
-	if err := json.NewDecoder(response.Body).Decode(&credits); err != nil {
-		return nil, fmt.Errorf("erro ao decodificar resposta: %w", err)
+	wanted := normalizeTitle(title)
+	var exact []tmdbSearchResult
+	for _, result := range candidates {
+		if normalizeTitle(result.Title) == wanted || normalizeTitle(result.OriginalTitle) == wanted {
+			exact = append(exact, result)
+		}
 	}
 
-	return &credits, nil
+	switch {
+	case len(exact) == 1:
+		return exact[0].ID, nil
+	case len(exact) > 1:
+		return 0, ErrAmbiguousMovie
+	case len(candidates) == 1 && year != "":
+		// Título traduzido ou com pontuação diferente, mas único no ano.
+		return candidates[0].ID, nil
+	case len(candidates) == 0:
+		return 0, ErrMovieNotFound
+	default:
+		return 0, ErrAmbiguousMovie
+	}
+}
+
+func normalizeTitle(title string) string {
+	return strings.ToLower(strings.Join(strings.Fields(title), " "))
 }
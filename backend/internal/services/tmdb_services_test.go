@@ -0,0 +1,103 @@
+package services
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMatchSearchResult(t *testing.T) {
+	solaris1972 := tmdbSearchResult{ID: 593, Title: "Solaris", OriginalTitle: "Солярис", ReleaseDate: "1972-03-20"}
+	solaris2002 := tmdbSearchResult{ID: 2103, Title: "Solaris", OriginalTitle: "Solaris", ReleaseDate: "2002-11-27"}
+	seventhSeal := tmdbSearchResult{ID: 490, Title: "O Sétimo Selo", OriginalTitle: "Det sjunde inseglet", ReleaseDate: "1957-02-16"}
+	wildStrawberries := tmdbSearchResult{ID: 614, Title: "Morangos Silvestres", OriginalTitle: "Smultronstället", ReleaseDate: "1957-12-26"}
+	crash := tmdbSearchResult{ID: 1640, Title: "Crash", OriginalTitle: "Crash", ReleaseDate: "2004-09-10"}
+	crashShort := tmdbSearchResult{ID: 99001, Title: "Crash", OriginalTitle: "Crash", ReleaseDate: "2004-05-01"}
+
+	tests := []struct {
+		name    string
+		results []tmdbSearchResult
+		title   string
+		year    string
+		want    int
+		err     error
+	}{
+		{
+			name:    "título exato no ano certo",
+			results: []tmdbSearchResult{solaris2002, solaris1972},
+			title:   "Solaris",
+			year:    "1972",
+			want:    593,
+		},
+		{
+			name:    "título com caixa e espaços diferentes",
+			results: []tmdbSearchResult{solaris1972},
+			title:   "  solaris ",
+			year:    "1972",
+			want:    593,
+		},
+		{
+			name:    "título traduzido único no ano",
+			results: []tmdbSearchResult{seventhSeal, solaris1972},
+			title:   "The Seventh Seal",
+			year:    "1957",
+			want:    490,
+		},
+		{
+			name:    "título traduzido com outros filmes no ano",
+			results: []tmdbSearchResult{seventhSeal, wildStrawberries},
+			title:   "The Seventh Seal",
+			year:    "1957",
+			err:     ErrAmbiguousMovie,
+		},
+		{
+			name:    "só o remake de outro ano",
+			results: []tmdbSearchResult{solaris2002},
+			title:   "Solaris",
+			year:    "1972",
+			err:     ErrMovieNotFound,
+		},
+		{
+			name:    "dois títulos exatos no mesmo ano",
+			results: []tmdbSearchResult{crash, crashShort},
+			title:   "Crash",
+			year:    "2004",
+			err:     ErrAmbiguousMovie,
+		},
+		{
+			name:    "sem ano e um único título exato",
+			results: []tmdbSearchResult{seventhSeal, solaris1972},
+			title:   "Solaris",
+			want:    593,
+		},
+		{
+			name:    "sem ano e vários resultados",
+			results: []tmdbSearchResult{solaris1972, solaris2002},
+			title:   "Solaris",
+			err:     ErrAmbiguousMovie,
+		},
+		{
+			name:    "sem ano e nenhum título exato",
+			results: []tmdbSearchResult{seventhSeal, wildStrawberries},
+			title:   "Bergman",
+			err:     ErrAmbiguousMovie,
+		},
+		{
+			name:  "sem resultados",
+			title: "Solaris",
+			year:  "1972",
+			err:   ErrMovieNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := matchSearchResult(tt.results, tt.title, tt.year)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("erro = %v, esperado %v", err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("id = %d, esperado %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+
+	"letterboxd-viewer-backend/config"
+	"letterboxd-viewer-backend/internal/database"
+	"letterboxd-viewer-backend/internal/services"
+)
+
+const (
+	exitOK      = 0
+	exitFailure = 1
+	exitUsage   = 2
+)
+
+type command struct {
+	Usage   string
+	Summary string
+	Run     func(ctx context.Context, args []string) error
+}
+
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"serve":   {Usage: "serve [-port N] [-config arquivo]", Summary: "sobe a API HTTP (padrão quando nenhum comando é informado)", Run: runServe},
+		"migrate": {Usage: "migrate [-json]", Summary: "aplica as migrações pendentes", Run: runMigrate},
+		"sync":    {Usage: "sync [-user nome] [-file feed.rss] [-json]", Summary: "importa o feed RSS do Letterboxd", Run: runSync},
+		"critics": {Usage: "critics [-user nome] [-json]", Summary: "importa os feeds dos críticos acompanhados", Run: runCritics},
+		"enrich":  {Usage: "enrich [-all] [-limit N] [-json]", Summary: "completa filmes com dados do TMDb", Run: runEnrich},
+		"import":  {Usage: "import [-user nome] [-json] <export.zip>", Summary: "importa o zip exportado pelo Letterboxd", Run: runImport},
+		"export":  {Usage: "export [-user nome] [-format csv|json|letterboxd] [-o arquivo]", Summary: "exporta o diário", Run: runExport},
+		"backup":  {Usage: "backup [-json] <arquivo.zip>", Summary: "gera um backup completo do banco", Run: runBackup},
+		"restore": {Usage: "restore [-json] <arquivo.zip>", Summary: "restaura um backup, substituindo os dados atuais", Run: runRestore},
+		"users":   {Usage: "users [list] | users set [-letterboxd nome] [-rss-file arquivo] <nome>", Summary: "lista usuários e configura o feed de cada um", Run: runUsers},
+		"config":  {Usage: "config [-json]", Summary: "mostra a configuração efetiva, sem segredos", Run: runConfig},
+		"doctor":  {Usage: "doctor [-json]", Summary: "verifica configuração, banco e TMDb", Run: runDoctor},
+	}
+}
+
+// usageError indica argumentos inválidos; o processo sai com código 2.
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string {
+	return e.msg
+}
+
+// app reúne a configuração, o banco e os serviços usados por todos os comandos.
+type app struct {
+	Config        *config.Config
+	DB            *sql.DB
+	TMDBService   *services.TMDBService
+	SyncService   *services.SyncService
+	CriticService *services.CriticService
+	Logger        *log.Logger
+}
+
+func run(args []string) int {
+	name := "serve"
+	if len(args) > 0 {
+		name, args = args[0], args[1:]
+	}
+
+	if name == "help" || name == "-h" || name == "--help" {
+		printUsage(os.Stdout)
+		return exitOK
+	}
+
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "comando desconhecido: %s\n\n", name)
+		printUsage(os.Stderr)
+		return exitUsage
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	err := cmd.Run(ctx, args)
+	var usage *usageError
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, flag.ErrHelp):
+		return exitOK
+	case errors.As(err, &usage):
+		fmt.Fprintf(os.Stderr, "%v\nuso: cinedrome %s\n", err, cmd.Usage)
+		return exitUsage
+	default:
+		fmt.Fprintf(os.Stderr, "erro: %v\n", err)
+		return exitFailure
+	}
+}
+
+func printUsage(w io.Writer) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(w, "uso: cinedrome <comando> [opções]")
+	fmt.Fprintln(w)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-8s %s\n", name, commands[name].Summary)
+	}
+}
+
+// requirements lista as configurações exigidas só pelo comando, como o token
+// do TMDb para quem consulta a API.
+func newApp(cfgFlags *config.Flags, logger *log.Logger, migrate bool, requirements ...config.Requirement) (*app, error) {
+	cfg, err := config.Load(cfgFlags, requirements...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao carregar configurações: %w", err)
+	}
+
+	db, err := database.ConnectDB(cfg.Database.URL)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao conectar ao banco de dados: %w", err)
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("erro ao verificar conexão com o banco de dados: %w", err)
+	}
+	logger.Println("Conexão com o banco de dados estabelecida com sucesso")
+
+	if migrate {
+		if err := database.Migrate(db); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("erro ao aplicar migrações: %w", err)
+		}
+	}
+
+	tmdbService := services.NewTMDBService(cfg.TMDB.AccessToken)
+	syncService := services.NewSyncService(db, tmdbService, cfg.Feeds.RSSFilePath, logger)
+	return &app{
+		Config:        cfg,
+		DB:            db,
+		TMDBService:   tmdbService,
+		SyncService:   syncService,
+		CriticService: services.NewCriticService(db, syncService, logger),
+		Logger:        logger,
+	}, nil
+}
+
+func (a *app) Close() {
+	if err := a.DB.Close(); err != nil {
+		a.Logger.Printf("Erro ao fechar conexão com o banco de dados: %v", err)
+	}
+}
+
+// Os comandos de manutenção registram o progresso no stderr, deixando o
+// stdout livre para a saída em JSON.
+func commandLogger() *log.Logger {
+	return log.New(os.Stderr, "[CLI] ", log.LstdFlags)
+}
+
+// Todos os comandos aceitam as flags de configuração (-config, -db, ...).
+func newFlagSet(name string) (*flag.FlagSet, *config.Flags) {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "uso: cinedrome %s\n", commands[name].Usage)
+		flags.PrintDefaults()
+	}
+	return flags, config.BindFlags(flags)
+}
+
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return &usageError{msg: err.Error()}
+	}
+	return nil
+}
+
+// printResult escreve v como JSON quando pedido; caso contrário usa a
+// versão legível.
+func printResult(asJSON bool, v any, human func(w io.Writer)) error {
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	}
+	human(os.Stdout)
+	return nil
+}
@@ -0,0 +1,535 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"letterboxd-viewer-backend/config"
+	"letterboxd-viewer-backend/internal/database"
+	"letterboxd-viewer-backend/internal/models"
+	"letterboxd-viewer-backend/internal/repositories"
+	"letterboxd-viewer-backend/internal/services"
+)
+
+func runMigrate(ctx context.Context, args []string) error {
+	flags, cfgFlags := newFlagSet("migrate")
+	asJSON := flags.Bool("json", false, "saída em JSON")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	a, err := newApp(cfgFlags, commandLogger(), false)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	from, err := database.CurrentVersion(a.DB)
+	if err != nil {
+		return err
+	}
+	if err := database.Migrate(a.DB); err != nil {
+		return fmt.Errorf("erro ao aplicar migrações: %w", err)
+	}
+
+	result := map[string]int{"from": from, "to": database.SchemaVersion()}
+	return printResult(*asJSON, result, func(w io.Writer) {
+		if from == result["to"] {
+			fmt.Fprintf(w, "Schema já está na versão %d\n", from)
+			return
+		}
+		fmt.Fprintf(w, "Schema migrado da versão %d para %d\n", from, result["to"])
+	})
+}
+
+// runSync sincroniza o usuário informado em -user; sem ele, -file vai para o
+// dono da instância e, sem nenhum dos dois, todos os usuários com feed
+// configurado são sincronizados.
+func runSync(ctx context.Context, args []string) error {
+	flags, cfgFlags := newFlagSet("sync")
+	file := flags.String("file", "", "arquivo RSS ou URL do feed (padrão: o feed configurado do usuário)")
+	username := flags.String("user", "", "usuário a sincronizar (padrão: todos)")
+	asJSON := flags.Bool("json", false, "saída em JSON")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	a, err := newApp(cfgFlags, commandLogger(), true, config.RequireTMDB)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	var users []models.User
+	if *username != "" || *file != "" {
+		user, err := resolveUser(a.DB, *username)
+		if err != nil {
+			return err
+		}
+		users = append(users, *user)
+	} else if users, err = repositories.GetUsers(a.DB); err != nil {
+		return err
+	}
+	if len(users) == 0 {
+		return errNoUsers
+	}
+
+	results := map[string]*services.SyncResult{}
+	for i := range users {
+		user := &users[i]
+		result, err := a.SyncService.SyncUser(ctx, user, *file)
+		if errors.Is(err, services.ErrNoFeedSource) && len(users) > 1 {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", user.Username, err)
+		}
+		results[user.Username] = result
+	}
+
+	return printResult(*asJSON, results, func(w io.Writer) {
+		for _, user := range users {
+			if result, ok := results[user.Username]; ok {
+				fmt.Fprintf(w, "%s: %d filmes inseridos, %d já existentes, %d falhas\n",
+					user.Username, result.Inserted, result.Skipped, len(result.Failed))
+			}
+		}
+	})
+}
+
+// runCritics importa os feeds dos críticos acompanhados pelo usuário de
+// -user ou, sem ele, por qualquer usuário.
+func runCritics(ctx context.Context, args []string) error {
+	flags, cfgFlags := newFlagSet("critics")
+	username := flags.String("user", "", "usuário cujos críticos serão sincronizados (padrão: todos)")
+	asJSON := flags.Bool("json", false, "saída em JSON")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	a, err := newApp(cfgFlags, commandLogger(), true, config.RequireTMDB)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	var user *models.User
+	if *username != "" {
+		if user, err = resolveUser(a.DB, *username); err != nil {
+			return err
+		}
+	}
+
+	results, err := a.CriticService.SyncFollowed(ctx, user)
+	if err != nil {
+		return err
+	}
+
+	return printResult(*asJSON, results, func(w io.Writer) {
+		critics := make([]string, 0, len(results))
+		for critic := range results {
+			critics = append(critics, critic)
+		}
+		sort.Strings(critics)
+		for _, critic := range critics {
+			result := results[critic]
+			fmt.Fprintf(w, "%s: %d entradas novas, %d já existentes, %d falhas\n",
+				critic, result.Inserted, result.Skipped, len(result.Failed))
+		}
+	})
+}
+
+func runEnrich(ctx context.Context, args []string) error {
+	flags, cfgFlags := newFlagSet("enrich")
+	all := flags.Bool("all", false, "reprocessa todos os filmes, não só os incompletos")
+	limit := flags.Int("limit", 500, "número máximo de filmes a processar")
+	asJSON := flags.Bool("json", false, "saída em JSON")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if *limit <= 0 {
+		return &usageError{msg: "-limit deve ser positivo"}
+	}
+
+	a, err := newApp(cfgFlags, commandLogger(), true, config.RequireTMDB)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	result, err := a.SyncService.EnrichMovies(ctx, *all, *limit)
+	if err != nil {
+		return err
+	}
+
+	if err := printResult(*asJSON, result, func(w io.Writer) {
+		fmt.Fprintf(w, "%d filmes atualizados, %d falhas\n", result.Enriched, len(result.Failed))
+	}); err != nil {
+		return err
+	}
+	if len(result.Failed) > 0 {
+		return fmt.Errorf("%d filmes não puderam ser enriquecidos", len(result.Failed))
+	}
+	return nil
+}
+
+func runImport(ctx context.Context, args []string) error {
+	flags, cfgFlags := newFlagSet("import")
+	username := flags.String("user", "", "usuário dono do diário (padrão: o dono da instância)")
+	asJSON := flags.Bool("json", false, "saída em JSON")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return &usageError{msg: "informe o zip exportado pelo Letterboxd"}
+	}
+
+	a, err := newApp(cfgFlags, commandLogger(), true, config.RequireTMDB)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	user, err := resolveUser(a.DB, *username)
+	if err != nil {
+		return err
+	}
+
+	result, err := a.SyncService.ImportLetterboxdExport(ctx, user.ID, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	return printResult(*asJSON, result, func(w io.Writer) {
+		fmt.Fprintf(w, "%d filmes importados, %d já existentes, %d falhas\n",
+			result.Inserted, result.Skipped, len(result.Failed))
+	})
+}
+
+func runExport(ctx context.Context, args []string) error {
+	flags, cfgFlags := newFlagSet("export")
+	format := flags.String("format", "csv", "csv, json ou letterboxd")
+	output := flags.String("o", "", "arquivo de saída (padrão: stdout)")
+	username := flags.String("user", "", "usuário dono do diário (padrão: o dono da instância)")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if _, ok := services.ExportFormats[*format]; !ok {
+		return &usageError{msg: "formato inválido: " + *format}
+	}
+
+	a, err := newApp(cfgFlags, commandLogger(), true)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	user, err := resolveUser(a.DB, *username)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("erro ao criar arquivo de exportação: %w", err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	return services.WriteExport(ctx, a.DB, user.ID, w, *format, nil)
+}
+
+func runBackup(ctx context.Context, args []string) error {
+	flags, cfgFlags := newFlagSet("backup")
+	asJSON := flags.Bool("json", false, "saída em JSON")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return &usageError{msg: "informe o arquivo de backup"}
+	}
+	path := flags.Arg(0)
+
+	a, err := newApp(cfgFlags, commandLogger(), true)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("erro ao criar arquivo de backup: %w", err)
+	}
+
+	manifest, err := database.Backup(ctx, a.DB, file)
+	if err != nil {
+		file.Close()
+		os.Remove(path)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("erro ao gravar arquivo de backup: %w", err)
+	}
+
+	return printResult(*asJSON, manifest, func(w io.Writer) {
+		for _, table := range manifest.Tables {
+			fmt.Fprintf(w, "%-12s %d linhas\n", table.Name, table.Rows)
+		}
+		fmt.Fprintf(w, "Backup gravado em %s (schema %d)\n", path, manifest.SchemaVersion)
+	})
+}
+
+func runRestore(ctx context.Context, args []string) error {
+	flags, cfgFlags := newFlagSet("restore")
+	asJSON := flags.Bool("json", false, "saída em JSON")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return &usageError{msg: "informe o arquivo de backup"}
+	}
+
+	a, err := newApp(cfgFlags, commandLogger(), true)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("erro ao abrir arquivo de backup: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("erro ao ler arquivo de backup: %w", err)
+	}
+
+	manifest, err := database.Restore(ctx, a.DB, file, info.Size())
+	if err != nil {
+		return err
+	}
+
+	return printResult(*asJSON, manifest, func(w io.Writer) {
+		for _, table := range manifest.Tables {
+			fmt.Fprintf(w, "%-12s %d linhas\n", table.Name, table.Rows)
+		}
+		fmt.Fprintf(w, "Backup de %s restaurado (schema %d)\n", manifest.CreatedAt.Format(time.RFC3339), manifest.SchemaVersion)
+	})
+}
+
+func runUsers(ctx context.Context, args []string) error {
+	flags, cfgFlags := newFlagSet("users")
+	letterboxd := flags.String("letterboxd", "", "usuário do Letterboxd cujo feed público é sincronizado")
+	rssFile := flags.String("rss-file", "", "arquivo RSS local, no lugar do feed público")
+	asJSON := flags.Bool("json", false, "saída em JSON")
+
+	action := "list"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		action, args = args[0], args[1:]
+	}
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	switch {
+	case action == "list" && flags.NArg() == 0:
+	case action == "set" && flags.NArg() == 1:
+	default:
+		return &usageError{msg: "use users [list] ou users set [-letterboxd nome] [-rss-file arquivo] <usuário>"}
+	}
+
+	a, err := newApp(cfgFlags, commandLogger(), true)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	if action == "list" {
+		users, err := repositories.GetUsers(a.DB)
+		if err != nil {
+			return err
+		}
+		return printResult(*asJSON, users, func(w io.Writer) {
+			for _, user := range users {
+				source, err := a.SyncService.FeedSource(&user)
+				if err != nil {
+					source = "-"
+				}
+				fmt.Fprintf(w, "%-20s %s\n", user.Username, source)
+			}
+		})
+	}
+
+	user, err := resolveUser(a.DB, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	// Só as flags informadas alteram o perfil; -letterboxd "" limpa o campo.
+	var invalid error
+	flags.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "letterboxd":
+			name := strings.ToLower(strings.TrimSpace(*letterboxd))
+			if name != "" && !services.ValidUsername(name) {
+				invalid = &usageError{msg: "usuário do Letterboxd inválido: " + *letterboxd}
+			}
+			user.LetterboxdUsername = name
+		case "rss-file":
+			user.RSSFilePath = *rssFile
+		}
+	})
+	if invalid != nil {
+		return invalid
+	}
+
+	if err := repositories.NewUserRepository(a.DB).UpdateProfile(user); err != nil {
+		return err
+	}
+
+	return printResult(*asJSON, user, func(w io.Writer) {
+		fmt.Fprintf(w, "Perfil de %s atualizado\n", user.Username)
+	})
+}
+
+var errNoUsers = errors.New("nenhum usuário cadastrado; crie a primeira conta pelo POST /api/auth/register")
+
+// resolveUser busca o usuário pelo nome ou, sem nome, o dono da instância.
+func resolveUser(db *sql.DB, username string) (*models.User, error) {
+	if username == "" {
+		user, err := repositories.GetDefaultUser(db)
+		if err == sql.ErrNoRows {
+			return nil, errNoUsers
+		}
+		return user, err
+	}
+
+	user, err := repositories.GetUserByUsername(db, services.NormalizeUsername(username))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("usuário %s não encontrado", username)
+	}
+	return user, err
+}
+
+func runConfig(ctx context.Context, args []string) error {
+	flags, cfgFlags := newFlagSet("config")
+	asJSON := flags.Bool("json", false, "saída em JSON")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	// Mesmo inválida, a configuração é mostrada para ajudar a achar o problema.
+	cfg, err := config.Load(cfgFlags)
+	if cfg != nil {
+		if printErr := printResult(*asJSON, cfg.Redacted(), func(w io.Writer) {
+			fmt.Fprintln(w, cfg)
+		}); printErr != nil {
+			return printErr
+		}
+	}
+	return err
+}
+
+type doctorCheck struct {
+	Name   string `json:"name"`
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail"`
+}
+
+// runDoctor não para no primeiro problema: todas as verificações são
+// executadas e o código de saída indica se alguma falhou.
+func runDoctor(ctx context.Context, args []string) error {
+	flags, cfgFlags := newFlagSet("doctor")
+	asJSON := flags.Bool("json", false, "saída em JSON")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	var checks []doctorCheck
+	check := func(name string, err error, detail string) {
+		if err != nil {
+			checks = append(checks, doctorCheck{Name: name, Detail: err.Error()})
+			return
+		}
+		checks = append(checks, doctorCheck{Name: name, OK: true, Detail: detail})
+	}
+
+	cfg, err := config.Load(cfgFlags)
+	var invalid *config.ValidationError
+	switch {
+	case errors.As(err, &invalid):
+		for _, problem := range invalid.Problems {
+			checks = append(checks, doctorCheck{Name: "config", Detail: problem})
+		}
+	case err != nil:
+		check("config", err, "")
+	default:
+		check("config", nil, "configuração válida")
+	}
+
+	if cfg != nil {
+		db, err := database.ConnectDB(cfg.Database.URL)
+		if err == nil {
+			defer db.Close()
+			err = db.PingContext(ctx)
+		}
+		check("database", err, "conexão estabelecida")
+
+		if err == nil {
+			version, err := database.CurrentVersion(db)
+			if err == nil && version < database.SchemaVersion() {
+				err = fmt.Errorf("schema na versão %d, esperado %d; execute migrate", version, database.SchemaVersion())
+			}
+			check("schema", err, fmt.Sprintf("versão %d", version))
+		}
+
+		if problems := config.RequireTMDB(cfg); len(problems) > 0 {
+			checks = append(checks, doctorCheck{Name: "tmdb", Detail: problems[0]})
+		} else {
+			check("tmdb", services.NewTMDBService(cfg.TMDB.AccessToken).Ping(ctx), "API acessível")
+		}
+
+		if path := cfg.Feeds.RSSFilePath; path != "" {
+			_, err := os.Stat(path)
+			check("rss_feed", err, path)
+		} else {
+			checks = append(checks, doctorCheck{Name: "rss_feed", OK: true, Detail: "RSS_FILE_PATH não configurado"})
+		}
+	}
+
+	failed := 0
+	for _, c := range checks {
+		if !c.OK {
+			failed++
+		}
+	}
+
+	if err := printResult(*asJSON, checks, func(w io.Writer) {
+		for _, c := range checks {
+			status := "ok"
+			if !c.OK {
+				status = "FALHA"
+			}
+			fmt.Fprintf(w, "%-9s %-5s %s\n", c.Name, status, c.Detail)
+		}
+	}); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d verificações falharam", failed)
+	}
+	return nil
+}
@@ -1,52 +1,90 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"log"
+	"net"
+	"net/http"
 	"os"
-	"os/signal"
-	"syscall"
+	"strconv"
 	"time"
 
 	"letterboxd-viewer-backend/config"
-	"letterboxd-viewer-backend/internal/database"
 	"letterboxd-viewer-backend/internal/handlers"
+	"letterboxd-viewer-backend/internal/middleware"
 	"letterboxd-viewer-backend/internal/services"
 
-	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 )
 
-func setupServer(db *sql.DB) *gin.Engine {
-	if os.Getenv("GIN_MODE") == "release" {
-		gin.SetMode(gin.ReleaseMode)
-	}
+func setupServer(cfg *config.Config, db *sql.DB, tmdbService *services.TMDBService) (*gin.Engine, *handlers.MovieHandler, error) {
+	gin.SetMode(cfg.Server.Mode)
 
 	router := gin.New()
 
 	router.Use(gin.Recovery())
 	router.Use(gin.Logger())
 
-	corsConfig := cors.Config{
-		AllowOrigins:     []string{"http://localhost:3000", "https://futuro-dominio-checar-ainda!.com"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Length", "Content-Type", "Authorization"},
-		AllowCredentials: true,
-		MaxAge:           12 * time.Hour,
+	// Os cabeçalhos de segurança vêm antes do CORS, que encerra os preflights.
+	router.Use(middleware.SecurityHeaders(cfg.Security))
+	corsMiddleware, err := middleware.CORS(cfg.CORS)
+	if err != nil {
+		return nil, nil, err
 	}
-	router.Use(cors.New(corsConfig))
+	router.Use(corsMiddleware)
 
 	logger := log.New(os.Stdout, "[API] ", log.LstdFlags)
 
-	tmdbToken := os.Getenv("TMDB_ACCESS_TOKEN")
-	if tmdbToken == "" {
-		logger.Fatal("TMDB_ACCESS_TOKEN não configurado")
-	}
-	tmdbService := services.NewTMDBService(tmdbToken)
+	authService := services.NewAuthService(db, cfg.Auth.SessionTTL.Duration(), cfg.Auth.AllowRegistration)
+	apiKeyService := services.NewAPIKeyService(db, cfg.Auth.APIKeyRateLimit)
+	router.Use(middleware.Authenticate(authService, logger))
+	router.Use(middleware.AuthenticateAPIKey(apiKeyService, logger))
+
+	authHandler := handlers.NewAuthHandler(authService, cfg.Security.TrustForwardedProto, logger)
+	authHandler.SetupRoutes(router)
 
-	movieHandler := handlers.NewMovieHandler(db, tmdbService, logger)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService, logger)
+	apiKeyHandler.SetupRoutes(router)
+
+	userHandler := handlers.NewUserHandler(db, logger)
+	userHandler.SetupRoutes(router)
+
+	syncService := services.NewSyncService(db, tmdbService, cfg.Feeds.RSSFilePath, logger)
+	movieHandler := handlers.NewMovieHandler(db, tmdbService, syncService, logger)
 	movieHandler.SetupRoutes(router)
 
+	criticService := services.NewCriticService(db, syncService, logger)
+	criticHandler := handlers.NewCriticHandler(db, criticService, logger)
+	criticHandler.SetupRoutes(router)
+
+	recommendationService := services.NewRecommendationService(db, tmdbService, logger)
+	recommendationHandler := handlers.NewRecommendationHandler(db, recommendationService, logger)
+	recommendationHandler.SetupRoutes(router)
+
+	searchHandler := handlers.NewSearchHandler(db, logger)
+	searchHandler.SetupRoutes(router)
+
+	statsHandler := handlers.NewStatsHandler(db, logger)
+	statsHandler.SetupRoutes(router)
+
+	peopleHandler := handlers.NewPeopleHandler(db, tmdbService, logger)
+	peopleHandler.SetupRoutes(router)
+
+	watchlistHandler := handlers.NewWatchlistHandler(db, tmdbService, cfg.Feeds.WatchlistRSSFilePath, logger)
+	watchlistHandler.SetupRoutes(router)
+
+	listHandler := handlers.NewListHandler(db, tmdbService, logger)
+	listHandler.SetupRoutes(router)
+
+	exportHandler := handlers.NewExportHandler(db, logger)
+	exportHandler.SetupRoutes(router)
+
+	feedHandler := handlers.NewFeedHandler(db, cfg.Server.PublicBaseURL, logger)
+	feedHandler.SetupRoutes(router)
+
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{
 			"status": "ok",
@@ -54,47 +92,86 @@ func setupServer(db *sql.DB) *gin.Engine {
 		})
 	})
 
-	return router
+	return router, movieHandler, nil
 }
 
 func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func runServe(ctx context.Context, args []string) error {
+	flags, cfgFlags := newFlagSet("serve")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
 	logger := log.New(os.Stdout, "[Main] ", log.LstdFlags)
 
-	if err := config.LoadConfig(); err != nil {
-		logger.Fatalf("Erro ao carregar configurações: %v", err)
+	a, err := newApp(cfgFlags, logger, true, config.RequireTMDB)
+	if err != nil {
+		return err
 	}
+	defer a.Close()
 
-	db, err := database.ConnectDB()
+	router, movieHandler, err := setupServer(a.Config, a.DB, a.TMDBService)
 	if err != nil {
-		logger.Fatalf("Erro ao conectar ao banco de dados: %v", err)
+		return err
 	}
-	defer db.Close()
+	port := strconv.Itoa(a.Config.Server.Port)
 
-	if err := db.Ping(); err != nil {
-		logger.Fatalf("Erro ao verificar conexão com o banco de dados: %v", err)
+	srv := &http.Server{
+		Addr:              ":" + port,
+		Handler:           router,
+		ReadHeaderTimeout: 10 * time.Second,
 	}
-	logger.Println("Conexão com o banco de dados estabelecida com sucesso")
 
-	router := setupServer(db)
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
-		logger.Printf("Variável PORT não configurada, usando porta padrão: %s", port)
+	ln, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar o servidor: %w", err)
 	}
+	logger.Printf("Servidor iniciando na porta %s...", port)
 
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	return serve(ctx, srv, ln, movieHandler, a.Config.Server.ShutdownTimeout.Duration(), logger)
+}
 
+// jobShutdowner cancela e aguarda as sincronizações iniciadas pelas rotas.
+type jobShutdowner interface {
+	Shutdown(ctx context.Context) error
+}
+
+// serve atende em ln até ctx ser cancelado. O encerramento inteiro cabe em
+// timeout: quatro quintos do prazo vão para as requisições em andamento e o
+// restante, no mínimo, para as sincronizações canceladas terminarem.
+func serve(ctx context.Context, srv *http.Server, ln net.Listener, jobs jobShutdowner, timeout time.Duration, logger *log.Logger) error {
+	serverErr := make(chan error, 1)
 	go func() {
-		addr := ":" + port
-		logger.Printf("Servidor iniciando na porta %s...", port)
-		if err := router.Run(addr); err != nil {
-			logger.Fatalf("Erro ao iniciar o servidor: %v", err)
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
 		}
 	}()
 
-	<-quit
+	select {
+	case err := <-serverErr:
+		return fmt.Errorf("erro ao iniciar o servidor: %w", err)
+	case <-ctx.Done():
+	}
 	logger.Println("Servidor está encerrando...")
 
+	deadlineCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	drainCtx, cancelDrain := context.WithTimeout(deadlineCtx, timeout*4/5)
+	defer cancelDrain()
+
+	// Primeiro para de aceitar conexões e aguarda as requisições em andamento;
+	// se o prazo estourar, as sincronizações restantes são canceladas.
+	if err := srv.Shutdown(drainCtx); err != nil {
+		logger.Printf("Requisições não finalizadas dentro de %s: %v", timeout*4/5, err)
+	}
+
+	if err := jobs.Shutdown(deadlineCtx); err != nil {
+		logger.Printf("Sincronizações em andamento não finalizaram: %v", err)
+	}
+
 	logger.Println("Servidor encerrado com sucesso")
+	return nil
 }
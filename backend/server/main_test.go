@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type fakeJobs struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	called chan struct{}
+}
+
+func newFakeJobs() *fakeJobs {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &fakeJobs{ctx: ctx, cancel: cancel, called: make(chan struct{})}
+}
+
+func (f *fakeJobs) Shutdown(ctx context.Context) error {
+	f.cancel()
+	close(f.called)
+	return nil
+}
+
+func startServe(t *testing.T, handler http.Handler, jobs jobShutdowner, timeout time.Duration) (string, context.CancelFunc, <-chan error) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- serve(ctx, &http.Server{Handler: handler}, ln, jobs, timeout, log.New(io.Discard, "", 0))
+	}()
+
+	return "http://" + ln.Addr().String(), cancel, done
+}
+
+func TestServeDrainsInFlightRequests(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		io.WriteString(w, "ok")
+	})
+
+	jobs := newFakeJobs()
+	url, stop, done := startServe(t, handler, jobs, 5*time.Second)
+
+	type result struct {
+		body string
+		err  error
+	}
+	responses := make(chan result, 1)
+	go func() {
+		resp, err := http.Get(url)
+		if err != nil {
+			responses <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		responses <- result{body: string(body), err: err}
+	}()
+
+	<-started
+	stop()
+
+	// O servidor já parou de aceitar conexões, mas ainda espera a requisição.
+	select {
+	case err := <-done:
+		t.Fatalf("serve retornou com requisição em andamento: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(release)
+
+	res := <-responses
+	if res.err != nil || res.body != "ok" {
+		t.Fatalf("requisição em andamento não completou: body=%q err=%v", res.body, res.err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("serve retornou erro: %v", err)
+	}
+	select {
+	case <-jobs.called:
+	default:
+		t.Fatal("as sincronizações não foram encerradas")
+	}
+}
+
+func TestServeCancelsJobsAfterDeadline(t *testing.T) {
+	jobs := newFakeJobs()
+	started := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-jobs.ctx.Done()
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	url, stop, done := startServe(t, handler, jobs, 200*time.Millisecond)
+	go func() {
+		if resp, err := http.Get(url); err == nil {
+			resp.Body.Close()
+		}
+	}()
+
+	<-started
+	begin := time.Now()
+	stop()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("serve não respeitou o prazo de encerramento")
+	}
+	if elapsed := time.Since(begin); elapsed > time.Second {
+		t.Fatalf("encerramento levou %s, prazo era 200ms", elapsed)
+	}
+}